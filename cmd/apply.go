@@ -0,0 +1,328 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/igorcosta/gh-lazy/pkg/github"
+	"github.com/igorcosta/gh-lazy/pkg/models"
+	"github.com/spf13/cobra"
+)
+
+var applyCmd = &cobra.Command{
+	Use:   "apply",
+	Short: "Reconcile edits in the tasks file onto existing milestones and issues",
+	Long: `Reconcile the tasks file with the repository.
+
+Milestones and issues that are missing are created. Existing ones whose title,
+body, description, due date, labels or assignees drifted from the tasks file are
+updated, and issues are moved to the milestone the file puts them in.
+
+With --close-removed, open milestones and issues that carry a gh-lazy key but
+are no longer in the tasks file are closed.`,
+	Annotations: map[string]string{githubAnnotation: "true"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, client := session.cfg, session.client
+
+		repoName := stringFlag(cmd, "repo", cfg.Repo)
+		if repoName == "" {
+			return fmt.Errorf("repository name is required. Use -r or --repo flag to specify the name")
+		}
+		closeRemoved, _ := cmd.Flags().GetBool("close-removed")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		tasks, err := loadValidatedTasks(cmd)
+		if err != nil {
+			return err
+		}
+
+		client.OnRateLimit(func(wait time.Duration) {
+			if wait > 0 {
+				color.Yellow("⏳ Waiting for rate limit reset (%s)...", wait.Round(time.Second))
+			}
+		})
+
+		ctx, cancel := commandContext(cmd, cfg, 30*time.Minute)
+		defer cancel()
+
+		if err := checkScopes(ctx, client, "repo"); err != nil {
+			return err
+		}
+
+		owner, repo, err := splitRepoName(repoName)
+		if err != nil {
+			return fmt.Errorf("invalid repository name: %w", err)
+		}
+
+		if dryRun {
+			color.Yellow("** Dry Run Mode Enabled **")
+			color.Yellow("No actual changes will occur.")
+			fmt.Println()
+		}
+
+		a := &applier{client: client, owner: owner, repo: repo, dryRun: dryRun}
+		if err := a.run(ctx, tasks, closeRemoved); err != nil {
+			return err
+		}
+		stopped := interruption(ctx)
+
+		fmt.Println()
+		color.Green("📊 Summary:")
+		color.Green("  ✅ Created: %d", a.created)
+		color.Yellow("  ✏️ Updated: %d", a.updated)
+		color.Cyan("  = Unchanged: %d", a.unchanged)
+		if closeRemoved {
+			color.Red("  🔒 Closed: %d", a.closed)
+		}
+		if a.failed > 0 {
+			color.Red("  ❌ Failed: %d", a.failed)
+		}
+		if stopped != nil {
+			return fmt.Errorf("%w before finishing; run apply again to reconcile the rest", stopped)
+		}
+		if a.failed > 0 {
+			return fmt.Errorf("%d changes failed", a.failed)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(applyCmd)
+	applyCmd.Flags().StringP("repo", "r", "", "The repository name (e.g., 'username/repo')")
+	applyCmd.Flags().StringP("tasks", "t", "", "Path to the tasks file (JSON, YAML, TOML or Markdown)")
+	applyCmd.Flags().String("format", "", "Tasks file format: json, yaml, toml or markdown (default: detected from the file extension)")
+	applyCmd.Flags().Bool("close-removed", false, "Close keyed milestones and issues that are no longer in the tasks file")
+	applyCmd.Flags().Bool("dry-run", false, "Show what would change without making changes")
+	applyCmd.MarkFlagRequired("tasks")
+}
+
+// applier reconciles a tasks file onto a repository and counts the outcome.
+type applier struct {
+	client github.API
+	owner  string
+	repo   string
+	dryRun bool
+
+	created, updated, unchanged, closed, failed int
+}
+
+func (a *applier) run(ctx context.Context, tasks *models.TasksFile, closeRemoved bool) error {
+	idx, err := github.LoadRepoIndex(ctx, a.client, a.owner, a.repo)
+	if err != nil {
+		return fmt.Errorf("failed to load repository %s/%s: %w", a.owner, a.repo, err)
+	}
+
+	if !a.dryRun {
+		if _, err := ensureLabels(ctx, a.client, idx, a.owner, a.repo, tasks); err != nil {
+			color.Yellow("⚠️ Failed to create labels: %v", err)
+		}
+	}
+
+	// Snapshot before applying so items created below are never closed.
+	milestones, issues := idx.Milestones(), idx.Issues()
+
+	keys := map[string]bool{}
+	for _, milestone := range tasks.Milestones {
+		if ctx.Err() != nil {
+			return nil
+		}
+		keys[milestone.Key] = true
+		target := milestone.Milestone
+		target.Number = a.applyMilestone(ctx, idx, milestone.Milestone)
+
+		for _, issue := range milestone.Issues {
+			keys[issue.Key] = true
+			if ctx.Err() != nil {
+				return nil
+			}
+			a.applyIssue(ctx, idx, issue, target)
+		}
+	}
+
+	// Closing relies on every key having been seen, so it never runs after
+	// an interruption.
+	if closeRemoved && ctx.Err() == nil {
+		a.closeRemoved(ctx, milestones, issues, keys)
+	}
+	return nil
+}
+
+// applyMilestone creates or updates a milestone and returns its number, or 0
+// if it doesn't exist (yet).
+func (a *applier) applyMilestone(ctx context.Context, idx *github.RepoIndex, milestone models.Milestone) int {
+	existing := idx.FindMilestone(milestone)
+	if existing == nil {
+		printChange(planChange{Action: actionCreate, Kind: "milestone", Name: milestone.Title})
+		if a.dryRun {
+			a.created++
+			return 0
+		}
+		number, err := a.client.CreateMilestone(ctx, a.owner, a.repo, milestone)
+		if err != nil {
+			color.Red("❌ Failed to create milestone %s: %v", milestone.Title, err)
+			a.failed++
+			return 0
+		}
+		indexMilestone(idx, milestone, number)
+		a.created++
+		return number
+	}
+
+	changes := milestoneChanges(*existing, milestone)
+	if len(changes) == 0 {
+		a.unchanged++
+		return existing.Number
+	}
+
+	printChange(planChange{Action: actionUpdate, Kind: "milestone", Name: milestone.Title, Comment: fmt.Sprintf("#%d", existing.Number), Fields: changes})
+	if !a.dryRun {
+		if err := a.client.UpdateMilestone(ctx, a.owner, a.repo, existing.Number, milestonePatch(milestone, changes)); err != nil {
+			color.Red("❌ Failed to update milestone %s: %v", milestone.Title, err)
+			a.failed++
+			return existing.Number
+		}
+	}
+	a.updated++
+	return existing.Number
+}
+
+func (a *applier) applyIssue(ctx context.Context, idx *github.RepoIndex, issue models.Issue, milestone models.Milestone) {
+	existing := idx.FindIssue(issue)
+	if existing == nil {
+		printChange(planChange{Action: actionCreate, Kind: "issue", Name: issue.Title, Comment: "in milestone " + milestone.Title})
+		if a.dryRun {
+			a.created++
+			return
+		}
+		number, err := a.client.CreateIssue(ctx, a.owner, a.repo, issue)
+		if err != nil {
+			color.Red("❌ Failed to create issue %s: %v", issue.Title, err)
+			a.failed++
+			return
+		}
+		indexIssue(idx, issue, number)
+		a.created++
+		if milestone.Number != 0 {
+			if err := a.client.UpdateIssueMilestone(ctx, a.owner, a.repo, number, milestone.Number); err != nil {
+				color.Red("❌ Failed to associate issue #%d with milestone #%d: %v", number, milestone.Number, err)
+				a.failed++
+				return
+			}
+			idx.SetIssueMilestone(number, milestone)
+		}
+		return
+	}
+
+	changes := issueChanges(*existing, issue, milestone)
+	// A milestone that couldn't be created has no number to move the issue
+	// to. In a dry run it would have been created, so the move is shown.
+	if milestone.Number == 0 && !a.dryRun {
+		changes = withoutField(changes, "milestone")
+	}
+	if len(changes) == 0 {
+		a.unchanged++
+		return
+	}
+
+	printChange(planChange{Action: actionUpdate, Kind: "issue", Name: issue.Title, Comment: fmt.Sprintf("#%d", existing.Number), Fields: changes})
+	if !a.dryRun {
+		if err := a.client.UpdateIssue(ctx, a.owner, a.repo, existing.Number, issuePatch(*existing, issue, milestone.Number, changes)); err != nil {
+			color.Red("❌ Failed to update issue #%d: %v", existing.Number, err)
+			a.failed++
+			return
+		}
+		if milestone.Number != 0 {
+			idx.SetIssueMilestone(existing.Number, milestone)
+		}
+	}
+	a.updated++
+}
+
+// withoutField returns changes without the change to field.
+func withoutField(changes []fieldChange, field string) []fieldChange {
+	var kept []fieldChange
+	for _, change := range changes {
+		if change.Field != field {
+			kept = append(kept, change)
+		}
+	}
+	return kept
+}
+
+// closeRemoved closes open milestones and issues whose gh-lazy key is no
+// longer in the tasks file. Items without a key are never touched.
+func (a *applier) closeRemoved(ctx context.Context, milestones []models.Milestone, issues []models.RepoIssue, keys map[string]bool) {
+	closed := map[string]interface{}{"state": "closed"}
+
+	for _, issue := range issues {
+		key := github.ExtractKey(issue.Body)
+		if key == "" || keys[key] || issue.State != "open" {
+			continue
+		}
+		printChange(planChange{Action: actionClose, Kind: "issue", Name: issue.Title, Comment: fmt.Sprintf("#%d, key %s", issue.Number, key)})
+		if !a.dryRun {
+			if err := a.client.UpdateIssue(ctx, a.owner, a.repo, issue.Number, closed); err != nil {
+				color.Red("❌ Failed to close issue #%d: %v", issue.Number, err)
+				a.failed++
+				continue
+			}
+		}
+		a.closed++
+	}
+
+	for _, milestone := range milestones {
+		if milestone.Key == "" || keys[milestone.Key] || milestone.State != "open" {
+			continue
+		}
+		printChange(planChange{Action: actionClose, Kind: "milestone", Name: milestone.Title, Comment: fmt.Sprintf("#%d, key %s", milestone.Number, milestone.Key)})
+		if !a.dryRun {
+			if err := a.client.UpdateMilestone(ctx, a.owner, a.repo, milestone.Number, closed); err != nil {
+				color.Red("❌ Failed to close milestone #%d: %v", milestone.Number, err)
+				a.failed++
+				continue
+			}
+		}
+		a.closed++
+	}
+}
+
+func milestonePatch(desired models.Milestone, changes []fieldChange) map[string]interface{} {
+	patch := map[string]interface{}{}
+	for _, change := range changes {
+		switch change.Field {
+		case "title":
+			patch["title"] = desired.Title
+		case "description", "key":
+			patch["description"] = github.WithKeyMarker(desired.Description, desired.Key)
+		case "due_on":
+			patch["due_on"] = desired.DueOn.UTC().Truncate(time.Second)
+		case "state":
+			patch["state"] = desired.State
+		}
+	}
+	return patch
+}
+
+func issuePatch(existing models.RepoIssue, desired models.Issue, milestoneNumber int, changes []fieldChange) map[string]interface{} {
+	patch := map[string]interface{}{}
+	for _, change := range changes {
+		switch change.Field {
+		case "title":
+			patch["title"] = desired.Title
+		case "body", "key":
+			patch["body"] = github.WithKeyMarker(desired.Body, desired.Key)
+		case "milestone":
+			if milestoneNumber != 0 {
+				patch["milestone"] = milestoneNumber
+			}
+		case "labels":
+			patch["labels"] = mergeNames(labelNames(existing), desired.Labels)
+		case "assignees":
+			patch["assignees"] = mergeNames(assigneeLogins(existing), desired.Assignees)
+		}
+	}
+	return patch
+}
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/igorcosta/gh-lazy/pkg/github"
+	"github.com/igorcosta/gh-lazy/pkg/github/fake"
+	"github.com/igorcosta/gh-lazy/pkg/models"
+)
+
+func TestApplyCreatesMissingTasks(t *testing.T) {
+	gh := newFake(t)
+	tasks := writeTasks(t)
+
+	out, err := run(t, gh, "apply", "--repo", "me/api", "--tasks", tasks)
+	if err != nil {
+		t.Fatalf("apply: %v\n%s", err, out)
+	}
+	assertContains(t, out, "Created: 3")
+	milestones := gh.Milestones("me/api")
+	for _, issue := range gh.Issues("me/api") {
+		if issue.Milestone == nil || issue.Milestone.Number != milestones[0].Number {
+			t.Errorf("issue %q is not in milestone Beta", issue.Title)
+		}
+	}
+
+	out, err = run(t, gh, "apply", "--repo", "me/api", "--tasks", tasks)
+	if err != nil {
+		t.Fatalf("second apply: %v\n%s", err, out)
+	}
+	assertContains(t, out, "Created: 0", "Updated: 0", "Unchanged: 3")
+}
+
+func TestApplyCountsFailedMilestoneAssociation(t *testing.T) {
+	gh := newFake(t)
+	gh.Inject(fake.Fault{Op: "UpdateIssueMilestone", Times: 1, Err: fake.Error(github.KindOther, "set milestone", "server error")})
+
+	out, err := run(t, gh, "apply", "--repo", "me/api", "--tasks", writeTasks(t))
+	if err == nil {
+		t.Fatalf("apply: err = nil, want the failed association reported\n%s", out)
+	}
+	assertContains(t, out, "Created: 3", "Failed: 1")
+}
+
+func TestApplyLeavesIssuesAloneWhenMilestoneCreateFails(t *testing.T) {
+	gh := newFake(t)
+	ctx := context.Background()
+	for _, issue := range []models.Issue{
+		{Title: "Write the docs", Body: "Cover every command", Labels: []string{"docs"}},
+		{Title: "Ship the installer", Body: "One line install"},
+	} {
+		if _, err := gh.CreateIssue(ctx, "me", "api", issue); err != nil {
+			t.Fatal(err)
+		}
+	}
+	gh.Inject(fake.Fault{Op: "CreateMilestone", Err: fake.Error(github.KindValidation, "create milestone", "rejected")})
+
+	out, err := run(t, gh, "apply", "--repo", "me/api", "--tasks", writeTasks(t))
+	if err == nil {
+		t.Fatalf("apply: err = nil, want the failed milestone reported\n%s", out)
+	}
+	assertContains(t, out, "Updated: 0", "Unchanged: 2", "Failed: 1")
+	if n := gh.Calls("UpdateIssue"); n != 0 {
+		t.Errorf("UpdateIssue called %d times, want 0", n)
+	}
+}
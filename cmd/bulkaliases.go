@@ -9,6 +9,7 @@ import (
 	"os/exec"
 	"strings"
 
+	"github.com/igorcosta/gh-lazy/pkg/config"
 	"github.com/manifoldco/promptui"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -171,9 +172,19 @@ func loadAliases() ([]Alias, error) {
 	return aliases, nil
 }
 
+// saveAliases writes the aliases back to the config file they came from, or
+// to the user config file if none has any yet.
 func saveAliases(aliases []Alias) error {
 	viper.Set("aliases", aliases)
-	return viper.WriteConfig()
+	name := "user"
+	if setting, ok, _ := config.Lookup("aliases"); ok && setting.Layer() != "" {
+		name = setting.Layer()
+	}
+	layer, err := config.FindLayer(name)
+	if err != nil {
+		return err
+	}
+	return config.Set(layer.Path, "aliases", aliases)
 }
 
 func listAliases(aliases []Alias) {
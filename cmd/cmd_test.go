@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/igorcosta/gh-lazy/pkg/config"
+	"github.com/igorcosta/gh-lazy/pkg/github"
+	"github.com/igorcosta/gh-lazy/pkg/github/fake"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// tasksYAML is a tasks file with one milestone of two issues.
+const tasksYAML = `projectTitle: Launch
+milestones:
+  - title: Beta
+    description: First public build
+    issues:
+      - title: Write the docs
+        body: Cover every command
+        labels: [docs]
+      - title: Ship the installer
+        body: One line install
+`
+
+// isolate keeps the test away from the user's config, tokens and profile.
+func isolate(t *testing.T) {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(dir, "xdg"))
+	t.Setenv("GH_TOKEN", "test-token")
+	t.Setenv("GH_HOST", "")
+	t.Setenv("LAZY_PROFILE", "")
+	config.SetFile(filepath.Join(dir, "config.yml"))
+	t.Cleanup(func() { config.SetFile("") })
+}
+
+// newFake returns a fake GitHub logged in as "me" with the repository me/api,
+// and isolates the test.
+func newFake(t *testing.T) *fake.GitHub {
+	t.Helper()
+	isolate(t)
+	gh := fake.New("me")
+	gh.AddRepo("me/api")
+	return gh
+}
+
+// writeTasks writes tasksYAML to a temporary file and returns its path.
+func writeTasks(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "tasks.yaml")
+	if err := os.WriteFile(path, []byte(tasksYAML), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// run runs gh lazy with args against gh and returns what it printed. A nil gh
+// leaves the real client in place.
+func run(t *testing.T, gh github.API, args ...string) (string, error) {
+	t.Helper()
+	out, err := os.Create(filepath.Join(t.TempDir(), "stdout"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer out.Close()
+
+	oldClient, oldStdout, oldOutput, oldNoColor := newClient, os.Stdout, color.Output, color.NoColor
+	if gh != nil {
+		newClient = func(string, github.ClientOptions) (github.API, error) { return gh, nil }
+	}
+	os.Stdout, color.Output, color.NoColor = out, out, true
+	defer func() {
+		newClient, os.Stdout, color.Output, color.NoColor = oldClient, oldStdout, oldOutput, oldNoColor
+		resetFlags(rootCmd)
+	}()
+
+	rootCmd.SetArgs(args)
+	runErr := rootCmd.Execute()
+
+	printed, err := os.ReadFile(out.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(printed), runErr
+}
+
+// resetFlags puts every flag of cmd and its subcommands back to its default,
+// since cobra keeps flag values between runs of the same command.
+func resetFlags(cmd *cobra.Command) {
+	reset := func(f *pflag.Flag) {
+		if !f.Changed {
+			return
+		}
+		if slice, ok := f.Value.(pflag.SliceValue); ok {
+			slice.Replace(nil)
+		} else {
+			f.Value.Set(f.DefValue)
+		}
+		f.Changed = false
+	}
+	cmd.Flags().VisitAll(reset)
+	cmd.PersistentFlags().VisitAll(reset)
+	for _, sub := range cmd.Commands() {
+		resetFlags(sub)
+	}
+}
+
+// rateLimitRecorder records the rate limit waits the fake reports before
+// passing them on to the command's handler.
+type rateLimitRecorder struct {
+	*fake.GitHub
+
+	mu    sync.Mutex
+	waits []time.Duration
+}
+
+func (r *rateLimitRecorder) OnRateLimit(handler github.RateLimitHandler) {
+	r.GitHub.OnRateLimit(func(wait time.Duration) {
+		r.mu.Lock()
+		r.waits = append(r.waits, wait)
+		r.mu.Unlock()
+		handler(wait)
+	})
+}
+
+func assertContains(t *testing.T, output string, want ...string) {
+	t.Helper()
+	for _, w := range want {
+		if !strings.Contains(output, w) {
+			t.Errorf("output does not contain %q:\n%s", w, output)
+		}
+	}
+}
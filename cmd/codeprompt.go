@@ -11,6 +11,7 @@ import (
 	"regexp"
 	"strings"
 
+	"github.com/igorcosta/gh-lazy/pkg/config"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -38,23 +39,15 @@ func init() {
 	codepromptCmd.Flags().BoolVar(&xmlOutput, "cxml", false, "Output in XML format similar to Claude's long context window")
 	codepromptCmd.Flags().BoolVar(&systemPrompt, "system-prompt", false, "Include system prompt from the configured file")
 
-	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is ./config.yml)")
+	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "repository config file (default is the nearest config.yml up to the git root)")
 }
 
 func initConfig() {
 	if cfgFile != "" {
-		viper.SetConfigFile(cfgFile)
-	} else {
-		viper.AddConfigPath(".")
-		viper.SetConfigName("config")
+		config.SetFile(cfgFile)
 	}
-
-	viper.AutomaticEnv()
-
-	if err := viper.ReadInConfig(); err == nil {
-		//fmt.Println("Using config file:", viper.ConfigFileUsed())
-	} else {
-		fmt.Println("Error reading config file:", err)
+	if err := config.Read(viper.GetViper()); err != nil {
+		fmt.Fprintln(os.Stderr, "Error reading config file:", err)
 	}
 }
 
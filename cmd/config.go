@@ -0,0 +1,176 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/igorcosta/gh-lazy/pkg/config"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Read and edit gh-lazy settings",
+	Long: `Read and edit gh-lazy settings.
+
+Settings are merged from, lowest precedence first:
+  default  built-in defaults
+  user     $XDG_CONFIG_HOME/gh-lazy/config.yml (~/.config/gh-lazy/config.yml)
+  repo     the nearest config.yml up to the git root, or --config
+  env      LAZY_* environment variables, e.g. LAZY_GITHUB_TIMEOUT for github.timeout
+
+Keys use dots for nesting, e.g. github.api_url.`,
+}
+
+var configGetCmd = &cobra.Command{
+	Use:   "get <key>",
+	Short: "Print the effective value of a setting",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		setting, ok, err := config.Lookup(args[0])
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("%s is not set", args[0])
+		}
+		return printSetting(cmd, setting, false)
+	},
+}
+
+var configListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Print every effective setting",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		settings, err := config.Settings()
+		if err != nil {
+			return err
+		}
+		for _, setting := range settings {
+			if err := printSetting(cmd, setting, true); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set <key> <value>",
+	Short: "Set a setting in a config file, keeping its comments",
+	Long: `Set a setting in a config file, keeping its comments.
+
+The value is parsed as YAML, so "true", "30" and "[a, b]" are a bool, a number
+and a list. Without --user or --repo the setting is written to the file it
+currently comes from, or the repository's config.yml if it isn't set in either.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		layer, err := configLayer(cmd, args[0], "repo")
+		if err != nil {
+			return err
+		}
+
+		var value interface{}
+		if err := yaml.Unmarshal([]byte(args[1]), &value); err != nil || value == nil {
+			value = args[1]
+		}
+		if err := config.Set(layer.Path, args[0], value); err != nil {
+			return err
+		}
+		fmt.Printf("Set %s in %s\n", args[0], layer.Path)
+		return nil
+	},
+}
+
+var configUnsetCmd = &cobra.Command{
+	Use:   "unset <key>",
+	Short: "Remove a setting from a config file",
+	Long: `Remove a setting from a config file.
+
+Without --user or --repo the setting is removed from the file it currently
+comes from.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		layer, err := configLayer(cmd, args[0], "")
+		if err != nil {
+			return err
+		}
+
+		removed, err := config.Unset(layer.Path, args[0])
+		if err != nil {
+			return err
+		}
+		if !removed {
+			return fmt.Errorf("%s is not set in %s", args[0], layer.Path)
+		}
+		fmt.Printf("Removed %s from %s\n", args[0], layer.Path)
+		return nil
+	},
+}
+
+// configLayer returns the config file named by --user or --repo, else the one
+// key comes from, else fallback.
+func configLayer(cmd *cobra.Command, key, fallback string) (config.Layer, error) {
+	name := fallback
+	if user, _ := cmd.Flags().GetBool("user"); user {
+		name = "user"
+	} else if repo, _ := cmd.Flags().GetBool("repo"); repo {
+		name = "repo"
+	} else {
+		setting, ok, err := config.Lookup(key)
+		if err != nil {
+			return config.Layer{}, err
+		}
+		if ok && setting.Layer() != "" {
+			name = setting.Layer()
+		}
+	}
+	if name == "" {
+		return config.Layer{}, fmt.Errorf("%s is not set in any config file", key)
+	}
+	return config.FindLayer(name)
+}
+
+// printSetting prints a setting's value, with its key when withKey is set and
+// its origin when --show-origin is.
+func printSetting(cmd *cobra.Command, setting config.Setting, withKey bool) error {
+	value, err := formatValue(setting.Value)
+	if err != nil {
+		return fmt.Errorf("failed to format %s: %w", setting.Key, err)
+	}
+	if withKey {
+		value = setting.Key + "=" + value
+	}
+	if showOrigin, _ := cmd.Flags().GetBool("show-origin"); showOrigin {
+		value = setting.Origin + "\t" + value
+	}
+	fmt.Println(value)
+	return nil
+}
+
+// formatValue prints scalars as they are and lists and mappings as JSON.
+func formatValue(value interface{}) (string, error) {
+	switch value.(type) {
+	case []interface{}, map[string]interface{}:
+		output, err := json.Marshal(value)
+		return string(output), err
+	default:
+		return strings.TrimSpace(fmt.Sprint(value)), nil
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configGetCmd, configListCmd, configSetCmd, configUnsetCmd)
+	for _, c := range []*cobra.Command{configGetCmd, configListCmd} {
+		c.Flags().Bool("show-origin", false, "Show the file or environment variable each value comes from")
+	}
+	for _, c := range []*cobra.Command{configSetCmd, configUnsetCmd} {
+		c.Flags().Bool("user", false, "Edit the user config file")
+		c.Flags().Bool("repo", false, "Edit the repository config file")
+		c.MarkFlagsMutuallyExclusive("user", "repo")
+	}
+}
@@ -9,72 +9,121 @@ import (
 	"time"
 
 	"github.com/fatih/color"
-	"github.com/igorcosta/gh-lazy/pkg/config"
 	"github.com/igorcosta/gh-lazy/pkg/github"
 	"github.com/igorcosta/gh-lazy/pkg/models"
+	"github.com/igorcosta/gh-lazy/pkg/schema"
+	"github.com/igorcosta/gh-lazy/pkg/state"
 	"github.com/igorcosta/gh-lazy/pkg/utils"
 	"github.com/schollz/progressbar/v3"
 	"github.com/spf13/cobra"
 )
 
 var createCmd = &cobra.Command{
-	Use:   "create",
-	Short: "Create project, milestones, and issues",
+	Use:         "create",
+	Short:       "Create project, milestones, and issues",
+	Annotations: map[string]string{githubAnnotation: "true"},
 	RunE: func(cmd *cobra.Command, args []string) error {
-		cfg, err := config.LoadConfig()
-		if err != nil {
-			return fmt.Errorf("failed to load config: %w", err)
+		cfg, client := session.cfg, session.client
+
+		repoName := stringFlag(cmd, "repo", cfg.Repo)
+		if repoName == "" {
+			return fmt.Errorf("repository name is required. Use -r or --repo flag to specify the name")
+		}
+
+		concurrency, _ := cmd.Flags().GetInt("concurrency")
+		if concurrency < 1 {
+			return fmt.Errorf("--concurrency must be at least 1")
 		}
 
-		repoName, err := cmd.Flags().GetString("repo")
+		tasks, err := loadValidatedTasks(cmd)
 		if err != nil {
-			return fmt.Errorf("failed to get repository name: %w", err)
+			return err
 		}
 
-		if repoName == "" {
-			return fmt.Errorf("repository name is required. Use -r or --repo flag to specify the name")
+		ctx, cancel := commandContext(cmd, cfg, 30*time.Minute)
+		defer cancel()
+
+		if err := checkScopes(ctx, client, "repo", "project"); err != nil {
+			return err
 		}
 
-		tasksFile, err := cmd.Flags().GetString("tasks")
+		owner, repo, err := splitRepoName(repoName)
 		if err != nil {
-			return fmt.Errorf("failed to get tasks file path: %w", err)
+			return fmt.Errorf("invalid repository name: %w", err)
 		}
 
-		if tasksFile == "" {
-			return fmt.Errorf("tasks file path is required. Use -t or --tasks flag to specify the path")
+		projectOwner, _ := cmd.Flags().GetString("owner")
+		if projectOwner == "" {
+			projectOwner = tasks.Owner
 		}
 
-		if _, err := os.Stat(tasksFile); os.IsNotExist(err) {
-			return fmt.Errorf("tasks file does not exist: %s", tasksFile)
+		var existingProject *models.Project
+		if projectIDOrURL := stringFlag(cmd, "project", cfg.Project); projectIDOrURL != "" {
+			projectNumber, err := utils.ParseProjectID(projectIDOrURL)
+			if err != nil {
+				return fmt.Errorf("failed to parse project ID: %w", err)
+			}
+			if projectOwner == "" {
+				projectOwner = github.ProjectOwnerFromURL(projectIDOrURL)
+			}
+			if projectOwner == "" {
+				projectOwner = cfg.Owner
+			}
+			if projectOwner == "" {
+				projectOwner = appOwner(cfg, repoName)
+			}
+			if projectOwner == "" {
+				projectOwner, err = client.GetProjectOwner(ctx, projectIDOrURL)
+				if err != nil {
+					return err
+				}
+			}
+			existingProject, err = client.GetProject(ctx, projectOwner, projectNumber)
+			if err != nil {
+				return withRemediation(fmt.Errorf("failed to find project: %w", err), client.Host())
+			}
 		}
 
-		absTasksFile, err := filepath.Abs(tasksFile)
-		if err != nil {
-			return fmt.Errorf("failed to get absolute path of tasks file: %w", err)
+		if projectOwner == "" {
+			projectOwner = cfg.Owner
 		}
-
-		token, err := utils.GetToken(cfg.TokenFile)
-		if err != nil {
-			utils.PrintUserGuide()
-			return fmt.Errorf("authentication error: %w", err)
+		if projectOwner == "" {
+			projectOwner = appOwner(cfg, repoName)
 		}
 
-		client, err := github.NewClient(token)
+		idx, err := github.LoadRepoIndex(ctx, client, owner, repo)
 		if err != nil {
-			return fmt.Errorf("failed to create GitHub client: %w", err)
+			return withRemediation(fmt.Errorf("failed to load repository %s: %w", repoName, err), client.Host())
 		}
 
-		tasks, err := utils.LoadTasksFile(absTasksFile)
+		st, err := loadCreateState(cmd, repoName, tasks.ProjectTitle)
 		if err != nil {
-			return fmt.Errorf("failed to load tasks file: %w", err)
+			return err
+		}
+		if existingProject != nil && st.ProjectURL != "" && st.ProjectURL != existingProject.URL {
+			return fmt.Errorf("the state file records project %s, but --project points at %s", st.ProjectURL, existingProject.URL)
 		}
 
-		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
-		defer cancel()
-
-		owner, repo, err := splitRepoName(repoName)
-		if err != nil {
-			return fmt.Errorf("invalid repository name: %w", err)
+		planOnly, _ := cmd.Flags().GetBool("plan")
+		if planOnly {
+			// A resumed run goes on with the project the state file records.
+			planProject := existingProject
+			if planProject == nil && st.ProjectURL != "" {
+				planProject, err = recordedProject(ctx, client, st.ProjectURL)
+				if err != nil {
+					return withRemediation(fmt.Errorf("failed to find project %s from the state file: %w", st.ProjectURL, err), client.Host())
+				}
+			}
+			plan, err := buildCreatePlan(ctx, client, idx, repoName, tasks, planProject)
+			if err != nil {
+				return fmt.Errorf("failed to plan changes: %w", err)
+			}
+			printPlan(plan)
+			if pending := plan.Pending(); pending > 0 {
+				cmd.SilenceUsage = true
+				return &ExitError{Code: 2, Err: fmt.Errorf("%d changes pending", pending)}
+			}
+			return nil
 		}
 
 		totalTasks := len(tasks.Milestones) + 2 // +2 for project creation and linking
@@ -95,78 +144,106 @@ var createCmd = &cobra.Command{
 				BarEnd:        "]",
 			}))
 
+		showRateLimitWaits(client, bar)
+
 		completed := 0
 		skipped := 0
 		failed := 0
-		createdIssues := []string{}
 
-		projectURL, err := client.CreateProject(ctx, tasks.ProjectTitle)
-		if err != nil {
-			return fmt.Errorf("failed to create project: %w", err)
+		saveState := func() {
+			if err := st.Save(); err != nil {
+				color.Yellow("⚠️ Failed to save state: %v", err)
+			}
+		}
+
+		if existingProject != nil {
+			st.ProjectURL = existingProject.URL
+			color.Cyan("📋 Adding to existing project %s", existingProject.URL)
 		}
+
+		if st.ProjectURL == "" {
+			projectURL, err := client.CreateProject(ctx, projectOwner, tasks.ProjectTitle)
+			if err != nil {
+				return withRemediation(fmt.Errorf("failed to create project: %w", err), client.Host())
+			}
+			st.ProjectURL = projectURL
+			saveState()
+		} else if resume, _ := cmd.Flags().GetBool("resume"); resume {
+			color.Cyan("↩️ Resuming with project %s", st.ProjectURL)
+		}
+		projectURL := st.ProjectURL
 		bar.Add(1)
 		completed++
 
 		// Extract project number from URL
 		parts := strings.Split(projectURL, "/")
 		projectNumber := parts[len(parts)-1]
-
-		// Link the project to the repository
-		err = client.LinkProjectToRepo(ctx, projectNumber, repoName)
+		projectOwner, err = client.GetProjectOwner(ctx, projectURL)
 		if err != nil {
-			color.Yellow("⚠️ Failed to link project to repository: %v", err)
-			skipped++
-		} else {
-			color.Green("✅ Project linked to repository %s", repoName)
-			completed++
+			return err
 		}
-		bar.Add(1)
 
-		for _, milestone := range tasks.Milestones {
-			milestoneNumber, err := createOrGetMilestone(ctx, client, owner, repo, milestone)
+		// Issues already on an existing board are skipped rather than added twice.
+		if existingProject != nil {
+			if err := github.LoadProjectItems(ctx, client, idx, projectOwner, projectNumber); err != nil {
+				return withRemediation(fmt.Errorf("failed to list project items: %w", err), client.Host())
+			}
+		}
+
+		// Link the project to the repository
+		if !st.ProjectLinked {
+			err = client.LinkProjectToRepo(ctx, projectOwner, projectNumber, repoName)
 			if err != nil {
-				color.Red("❌ Failed to create/get milestone %s: %v", milestone.Title, err)
-				failed++
-				bar.Add(1)
-				continue
+				color.Yellow("⚠️ Failed to link project to repository: %v", withRemediation(err, client.Host()))
+				skipped++
+			} else {
+				color.Green("✅ Project linked to repository %s", repoName)
+				st.ProjectLinked = true
+				saveState()
+				completed++
 			}
-			bar.Add(1)
+		} else {
 			completed++
+		}
+		bar.Add(1)
 
-			for _, issue := range milestone.Issues {
-				issueNumber, err := createOrGetIssue(ctx, client, owner, repo, issue)
-				if err != nil {
-					color.Red("❌ Failed to create/get issue %s: %v", issue.Title, err)
-					failed++
-					bar.Add(1)
-					continue
-				}
-
-				err = client.UpdateIssueMilestone(ctx, owner, repo, issueNumber, milestoneNumber)
-				if err != nil {
-					color.Yellow("⚠️ Failed to associate issue #%d with milestone #%d: %v", issueNumber, milestoneNumber, err)
-					skipped++
-				}
-
-				issueURL := fmt.Sprintf("https://github.com/%s/%s/issues/%d", owner, repo, issueNumber)
-				err = client.AddIssueToProject(ctx, projectURL, issueURL)
-				if err != nil {
-					color.Yellow("⚠️ Failed to add issue #%d to project: %v", issueNumber, err)
-					skipped++
-				}
+		createdLabels, err := ensureLabels(ctx, client, idx, owner, repo, tasks)
+		if err != nil {
+			color.Yellow("⚠️ Failed to create labels: %v", err)
+			skipped++
+		} else if createdLabels > 0 {
+			color.Green("✅ Created %d labels", createdLabels)
+		}
 
-				createdIssues = append(createdIssues, issueURL)
-				bar.Add(1)
-				completed++
-			}
+		c := &creator{
+			client:      client,
+			idx:         idx,
+			st:          st,
+			bar:         bar,
+			owner:       owner,
+			repo:        repo,
+			projectURL:  projectURL,
+			concurrency: concurrency,
 		}
+		c.run(ctx, tasks.Milestones)
+		completed += c.completed
+		skipped += c.skipped
+		failed += c.failed
+
+		stopped := interruption(ctx)
+		st.Completed = failed == 0 && stopped == nil && createFinished(st, tasks)
+		saveState()
 
 		bar.Finish()
 		fmt.Println()
 
-		color.Green("✅ Project created successfully: %s", projectURL)
+		if existingProject != nil {
+			color.Green("✅ Project updated successfully: %s", projectURL)
+		} else {
+			color.Green("✅ Project created successfully: %s", projectURL)
+		}
 		fmt.Println("Created issues:")
-		for _, issueURL := range createdIssues {
+		for _, issueURL := range c.createdIssues() {
 			color.Cyan("  • %s", issueURL)
 		}
 
@@ -177,6 +254,13 @@ var createCmd = &cobra.Command{
 		color.Red("  ❌ Failed tasks: %d", failed)
 		color.Cyan("  🔗 Project URL: %s", projectURL)
 
+		stateFile, _ := cmd.Flags().GetString("state-file")
+		if stopped != nil {
+			return fmt.Errorf("%w before finishing; progress is saved in %s, run again with --resume to continue", stopped, stateFile)
+		}
+		if !st.Completed {
+			color.Yellow("💡 Some tasks were skipped; progress is saved in %s, run again with --resume to retry them", stateFile)
+		}
 		return nil
 	},
 }
@@ -184,17 +268,21 @@ var createCmd = &cobra.Command{
 func init() {
 	rootCmd.AddCommand(createCmd)
 	createCmd.Flags().StringP("repo", "r", "", "The repository name (e.g., 'username/repo')")
-	createCmd.Flags().StringP("tasks", "t", "", "Path to the tasks JSON file")
-	createCmd.MarkFlagRequired("repo")
+	createCmd.Flags().StringP("tasks", "t", "", "Path to the tasks file (JSON, YAML, TOML or Markdown)")
+	createCmd.Flags().String("format", "", "Tasks file format: json, yaml, toml or markdown (default: detected from the file extension)")
+	createCmd.Flags().Bool("plan", false, "Show what create would change without writing anything; exits with code 2 if changes are pending")
+	createCmd.Flags().String("owner", "", "User or organization that owns the project (default: the tasks file owner, or you; the repository owner for a GitHub App)")
+	createCmd.Flags().StringP("project", "p", "", "Add to an existing project (number or URL) instead of creating one")
+	createCmd.Flags().String("state-file", state.DefaultFile, "Path to the file recording what create produced")
+	createCmd.Flags().Bool("resume", false, "Resume an interrupted run from the state file")
+	createCmd.Flags().Int("concurrency", defaultConcurrency, "How many milestones and issues to work on at once")
 	createCmd.MarkFlagRequired("tasks")
 }
 
-func createOrGetMilestone(ctx context.Context, client *github.Client, owner, repo string, milestoneWithIssues models.MilestoneWithIssues) (int, error) {
-	existingMilestone, err := client.GetMilestoneByTitle(ctx, owner, repo, milestoneWithIssues.Title)
-	if err != nil {
-		return 0, fmt.Errorf("checking existing milestone: %w", err)
-	}
-	if existingMilestone != nil {
+// createOrGetMilestone returns the number of the milestone matching the tasks
+// file entry, creating it if the index has no match.
+func createOrGetMilestone(ctx context.Context, client github.API, idx *github.RepoIndex, owner, repo string, milestoneWithIssues models.MilestoneWithIssues) (int, error) {
+	if existingMilestone := idx.FindMilestone(milestoneWithIssues.Milestone); existingMilestone != nil {
 		return existingMilestone.Number, nil
 	}
 
@@ -202,15 +290,24 @@ func createOrGetMilestone(ctx context.Context, client *github.Client, owner, rep
 	if err != nil {
 		return 0, fmt.Errorf("creating milestone: %w", err)
 	}
+
+	indexMilestone(idx, milestoneWithIssues.Milestone, number)
 	return number, nil
 }
 
-func createOrGetIssue(ctx context.Context, client *github.Client, owner, repo string, issue models.Issue) (int, error) {
-	existingIssue, err := client.GetIssueByTitle(ctx, owner, repo, issue.Title)
-	if err != nil {
-		return 0, fmt.Errorf("checking existing issue: %w", err)
-	}
-	if existingIssue != nil {
+// indexMilestone adds a milestone just created as number to idx, as GitHub
+// now has it.
+func indexMilestone(idx *github.RepoIndex, milestone models.Milestone, number int) {
+	milestone.Number = number
+	milestone.State = "open"
+	milestone.Description = github.WithKeyMarker(milestone.Description, milestone.Key)
+	idx.AddMilestone(milestone)
+}
+
+// createOrGetIssue returns the number of the issue matching the tasks file
+// entry, creating it if the index has no match.
+func createOrGetIssue(ctx context.Context, client github.API, idx *github.RepoIndex, owner, repo string, issue models.Issue) (int, error) {
+	if existingIssue := idx.FindIssue(issue); existingIssue != nil {
 		return existingIssue.Number, nil
 	}
 
@@ -218,9 +315,174 @@ func createOrGetIssue(ctx context.Context, client *github.Client, owner, repo st
 	if err != nil {
 		return 0, fmt.Errorf("creating issue: %w", err)
 	}
+
+	indexIssue(idx, issue, number)
 	return number, nil
 }
 
+// indexIssue adds an issue just created as number to idx, as GitHub now has it.
+func indexIssue(idx *github.RepoIndex, issue models.Issue, number int) {
+	created := models.RepoIssue{
+		Number: number,
+		Title:  issue.Title,
+		Body:   github.WithKeyMarker(issue.Body, issue.Key),
+		State:  "open",
+	}
+	for _, name := range issue.Labels {
+		created.Labels = append(created.Labels, models.Label{Name: name})
+	}
+	for _, login := range issue.Assignees {
+		created.Assignees = append(created.Assignees, models.User{Login: login})
+	}
+	idx.AddIssue(created)
+}
+
+// loadCreateState returns the state to record this run in. With --resume it
+// continues the previous run; otherwise it refuses to start over an
+// unfinished run for the same repository, which would create a second project.
+func loadCreateState(cmd *cobra.Command, repoName, projectTitle string) (*state.State, error) {
+	stateFile, _ := cmd.Flags().GetString("state-file")
+	resume, _ := cmd.Flags().GetBool("resume")
+
+	previous, err := state.Load(stateFile)
+	if err != nil {
+		return nil, err
+	}
+
+	if resume {
+		if previous == nil {
+			return nil, fmt.Errorf("no state file found at %s; nothing to resume", stateFile)
+		}
+		if previous.Repo != repoName {
+			return nil, fmt.Errorf("state file %s belongs to %s, not %s", stateFile, previous.Repo, repoName)
+		}
+		return previous, nil
+	}
+
+	if previous != nil && !previous.Completed && previous.Repo == repoName {
+		return nil, fmt.Errorf("a previous create run for %s did not finish (see %s). Use --resume to continue it, or delete the file to start over", repoName, stateFile)
+	}
+	return state.New(stateFile, repoName, projectTitle), nil
+}
+
+// recordedProject looks up the project at projectURL, as recorded in a state
+// file.
+func recordedProject(ctx context.Context, client github.API, projectURL string) (*models.Project, error) {
+	owner, err := client.GetProjectOwner(ctx, projectURL)
+	if err != nil {
+		return nil, err
+	}
+	parts := strings.Split(projectURL, "/")
+	return client.GetProject(ctx, owner, parts[len(parts)-1])
+}
+
+// createFinished reports whether st records everything the tasks file asks
+// for: the project linked, and every milestone and issue created, each issue in
+// its milestone and on the board. Anything skipped along the way leaves the run
+// unfinished, so it can only be resumed rather than started over.
+func createFinished(st *state.State, tasks *models.TasksFile) bool {
+	if st.ProjectURL == "" || !st.ProjectLinked {
+		return false
+	}
+	for _, milestone := range tasks.Milestones {
+		milestoneState := st.Milestones[state.TaskID(milestone.Key, milestone.Title)]
+		if milestoneState == nil || milestoneState.Number == 0 {
+			return false
+		}
+		for _, issue := range milestone.Issues {
+			issueState := st.Issues[state.TaskID(issue.Key, issue.Title)]
+			if issueState == nil || issueState.Number == 0 ||
+				issueState.Milestone != milestoneState.Number || issueState.ProjectItemID == "" {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// loadValidatedTasks reads the --tasks file in the --format format and
+// rejects it before any GitHub call if validation finds errors.
+func loadValidatedTasks(cmd *cobra.Command) (*models.TasksFile, error) {
+	tasksFile, err := cmd.Flags().GetString("tasks")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tasks file path: %w", err)
+	}
+
+	if tasksFile == "" {
+		return nil, fmt.Errorf("tasks file path is required. Use -t or --tasks flag to specify the path")
+	}
+
+	if _, err := os.Stat(tasksFile); os.IsNotExist(err) {
+		return nil, fmt.Errorf("tasks file does not exist: %s", tasksFile)
+	}
+
+	absTasksFile, err := filepath.Abs(tasksFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get absolute path of tasks file: %w", err)
+	}
+
+	format, err := cmd.Flags().GetString("format")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tasks file format: %w", err)
+	}
+
+	problems, err := schema.ValidateFile(absTasksFile, format)
+	if err != nil {
+		return nil, fmt.Errorf("failed to validate tasks file: %w", err)
+	}
+	printProblems(problems)
+	if schema.HasErrors(problems) {
+		return nil, fmt.Errorf("tasks file %s is invalid. Run 'gh lazy validate -t %s' for details", tasksFile, tasksFile)
+	}
+
+	tasks, err := utils.LoadTasksFile(absTasksFile, format)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load tasks file: %w", err)
+	}
+	return tasks, nil
+}
+
+// ensureLabels creates the labels declared in the tasks file or used by its
+// issues that do not exist in the repository yet, and returns how many it created.
+func ensureLabels(ctx context.Context, client github.API, idx *github.RepoIndex, owner, repo string, tasks *models.TasksFile) (int, error) {
+	created := 0
+	for _, label := range wantedLabels(tasks) {
+		if idx.HasLabel(label.Name) {
+			continue
+		}
+		if err := client.CreateLabel(ctx, owner, repo, label); err != nil {
+			return created, err
+		}
+		idx.AddLabel(label.Name)
+		created++
+	}
+	return created, nil
+}
+
+// wantedLabels returns the labels declared in the tasks file followed by any
+// other labels its issues use, without duplicates.
+func wantedLabels(tasks *models.TasksFile) []models.Label {
+	var wanted []models.Label
+	seen := map[string]bool{}
+	for _, label := range tasks.Labels {
+		if !seen[strings.ToLower(label.Name)] {
+			seen[strings.ToLower(label.Name)] = true
+			wanted = append(wanted, label)
+		}
+	}
+	for _, milestone := range tasks.Milestones {
+		for _, issue := range milestone.Issues {
+			for _, name := range issue.Labels {
+				if !seen[strings.ToLower(name)] {
+					seen[strings.ToLower(name)] = true
+					wanted = append(wanted, models.Label{Name: name})
+				}
+			}
+		}
+	}
+	return wanted
+}
+
 func splitRepoName(repoName string) (string, string, error) {
 	parts := strings.Split(repoName, "/")
 	if len(parts) != 2 {
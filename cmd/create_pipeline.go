@@ -0,0 +1,280 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/fatih/color"
+	"github.com/igorcosta/gh-lazy/pkg/github"
+	"github.com/igorcosta/gh-lazy/pkg/models"
+	"github.com/igorcosta/gh-lazy/pkg/state"
+	"github.com/schollz/progressbar/v3"
+)
+
+// defaultConcurrency is how many milestones and issues create works on at once.
+const defaultConcurrency = 4
+
+// creator creates the milestones and issues of a tasks file. Milestones are
+// worked on concurrently, but the issues of one milestone are created in file
+// order so their numbers stay in order. Setting each issue's milestone and
+// adding it to the project happens on a separate pool of workers.
+type creator struct {
+	client      github.API
+	idx         *github.RepoIndex
+	st          *state.State
+	bar         *progressbar.ProgressBar
+	owner       string
+	repo        string
+	projectURL  string
+	concurrency int
+
+	// mu guards the counters, the issue URLs, the hints and everything in st.
+	mu                         sync.Mutex
+	completed, skipped, failed int
+	issueURLs                  [][]string
+	hints                      map[string]bool
+}
+
+// issueJob is an issue whose number is known and that still has to be put in
+// its milestone and on the project board.
+type issueJob struct {
+	issueState     *state.IssueState
+	milestone      models.Milestone
+	milestoneIndex int
+	issueIndex     int
+}
+
+func (c *creator) run(ctx context.Context, milestones []models.MilestoneWithIssues) {
+	c.issueURLs = make([][]string, len(milestones))
+	for i, milestone := range milestones {
+		c.issueURLs[i] = make([]string, len(milestone.Issues))
+	}
+
+	jobs := make(chan issueJob)
+	var workers sync.WaitGroup
+	for i := 0; i < c.concurrency; i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for job := range jobs {
+				c.finishIssue(ctx, job)
+			}
+		}()
+	}
+
+	slots := make(chan struct{}, c.concurrency)
+	var producers sync.WaitGroup
+	for i, milestone := range milestones {
+		if ctx.Err() != nil {
+			// Interrupted: what's left is skipped, and --resume picks it up.
+			c.mu.Lock()
+			c.skipped += 1 + len(milestone.Issues)
+			c.mu.Unlock()
+			c.bar.Add(1 + len(milestone.Issues))
+			continue
+		}
+		producers.Add(1)
+		slots <- struct{}{}
+		go func() {
+			defer producers.Done()
+			defer func() { <-slots }()
+			c.createMilestone(ctx, i, milestone, jobs)
+		}()
+	}
+
+	producers.Wait()
+	close(jobs)
+	workers.Wait()
+}
+
+// createMilestone creates a milestone and then its issues one at a time,
+// handing each issue to the workers once it has a number.
+func (c *creator) createMilestone(ctx context.Context, milestoneIndex int, milestone models.MilestoneWithIssues, jobs chan<- issueJob) {
+	c.mu.Lock()
+	milestoneState := c.st.Milestone(milestone.Key, milestone.Title)
+	milestoneNumber := milestoneState.Number
+	c.mu.Unlock()
+
+	if milestoneNumber == 0 {
+		number, err := createOrGetMilestone(ctx, c.client, c.idx, c.owner, c.repo, milestone)
+		if err != nil {
+			// Its issues have nowhere to go, so they are skipped with it.
+			c.mu.Lock()
+			switch {
+			case ctx.Err() != nil:
+				c.skipped++
+			case errors.Is(err, github.ErrValidation):
+				color.Yellow("⚠️ GitHub rejected milestone %s: %v", milestone.Title, err)
+				c.skipped++
+			default:
+				color.Red("❌ Failed to create/get milestone %s: %v", milestone.Title, err)
+				c.printRemediation(err)
+				c.failed++
+			}
+			c.skipped += len(milestone.Issues)
+			c.mu.Unlock()
+			c.bar.Add(1 + len(milestone.Issues))
+			return
+		}
+		milestoneNumber = number
+		c.update(func() { milestoneState.Number = number })
+	}
+	c.count(&c.completed)
+	c.bar.Add(1)
+
+	target := milestone.Milestone
+	target.Number = milestoneNumber
+
+	for i, issue := range milestone.Issues {
+		if ctx.Err() != nil {
+			c.mu.Lock()
+			c.skipped += len(milestone.Issues) - i
+			c.mu.Unlock()
+			c.bar.Add(len(milestone.Issues) - i)
+			return
+		}
+
+		c.mu.Lock()
+		issueState := c.st.Issue(issue.Key, issue.Title)
+		issueNumber := issueState.Number
+		c.mu.Unlock()
+
+		if issueNumber == 0 {
+			number, err := createOrGetIssue(ctx, c.client, c.idx, c.owner, c.repo, issue)
+			if err != nil {
+				switch {
+				case ctx.Err() != nil:
+					c.count(&c.skipped)
+				case errors.Is(err, github.ErrValidation):
+					color.Yellow("⚠️ GitHub rejected issue %s: %v", issue.Title, err)
+					c.count(&c.skipped)
+				default:
+					color.Red("❌ Failed to create/get issue %s: %v", issue.Title, err)
+					c.mu.Lock()
+					c.printRemediation(err)
+					c.failed++
+					c.mu.Unlock()
+				}
+				c.bar.Add(1)
+				continue
+			}
+			c.update(func() {
+				issueState.Number = number
+				issueState.URL = c.client.IssueURL(c.owner, c.repo, number)
+			})
+		}
+
+		jobs <- issueJob{issueState: issueState, milestone: target, milestoneIndex: milestoneIndex, issueIndex: i}
+	}
+}
+
+// finishIssue puts an issue in its milestone and on the project board. The
+// issue counts as skipped if either fails or it was on the board already, and
+// as completed otherwise.
+func (c *creator) finishIssue(ctx context.Context, job issueJob) {
+	c.mu.Lock()
+	issueNumber := job.issueState.Number
+	issueURL := job.issueState.URL
+	currentMilestone := job.issueState.Milestone
+	projectItemID := job.issueState.ProjectItemID
+	c.mu.Unlock()
+
+	// The issue exists, so an interrupted run leaves the rest to --resume.
+	if ctx.Err() != nil {
+		c.count(&c.skipped)
+		c.bar.Add(1)
+		return
+	}
+
+	skipped := false
+	if currentMilestone != job.milestone.Number {
+		err := c.client.UpdateIssueMilestone(ctx, c.owner, c.repo, issueNumber, job.milestone.Number)
+		if err != nil {
+			color.Yellow("⚠️ Failed to associate issue #%d with milestone #%d: %v", issueNumber, job.milestone.Number, err)
+			c.mu.Lock()
+			c.printRemediation(err)
+			c.mu.Unlock()
+			skipped = true
+		} else {
+			c.idx.SetIssueMilestone(issueNumber, job.milestone)
+			c.update(func() { job.issueState.Milestone = job.milestone.Number })
+		}
+	}
+
+	repoName := fmt.Sprintf("%s/%s", c.owner, c.repo)
+	if itemID, ok := c.idx.ProjectItem(repoName, issueNumber); ok && projectItemID == "" {
+		c.update(func() { job.issueState.ProjectItemID = itemID })
+		skipped = true
+	} else if projectItemID == "" {
+		itemID, err := c.client.AddIssueToProject(ctx, c.projectURL, issueURL)
+		if err != nil {
+			color.Yellow("⚠️ Failed to add issue #%d to project: %v", issueNumber, err)
+			c.mu.Lock()
+			c.printRemediation(err)
+			c.mu.Unlock()
+			skipped = true
+		} else {
+			c.idx.AddProjectItem(repoName, issueNumber, itemID)
+			c.update(func() { job.issueState.ProjectItemID = itemID })
+		}
+	}
+
+	c.mu.Lock()
+	c.issueURLs[job.milestoneIndex][job.issueIndex] = issueURL
+	if skipped {
+		c.skipped++
+	} else {
+		c.completed++
+	}
+	c.mu.Unlock()
+	c.bar.Add(1)
+}
+
+// createdIssues returns the URLs of the issues that were handled, in tasks
+// file order.
+func (c *creator) createdIssues() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var urls []string
+	for _, milestone := range c.issueURLs {
+		for _, url := range milestone {
+			if url != "" {
+				urls = append(urls, url)
+			}
+		}
+	}
+	return urls
+}
+
+// printRemediation prints advice on fixing err, once per piece of advice, so
+// a token missing a scope doesn't repeat the same hint for every issue. The
+// caller must hold mu.
+func (c *creator) printRemediation(err error) {
+	hint := remediation(err, c.client.Host())
+	if hint == "" || c.hints[hint] {
+		return
+	}
+	if c.hints == nil {
+		c.hints = map[string]bool{}
+	}
+	c.hints[hint] = true
+	color.Yellow("💡 %s", hint)
+}
+
+func (c *creator) count(counter *int) {
+	c.mu.Lock()
+	*counter++
+	c.mu.Unlock()
+}
+
+// update changes the state under the lock and saves it.
+func (c *creator) update(change func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	change()
+	if err := c.st.Save(); err != nil {
+		color.Yellow("⚠️ Failed to save state: %v", err)
+	}
+}
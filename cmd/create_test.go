@@ -0,0 +1,246 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/igorcosta/gh-lazy/pkg/github"
+	"github.com/igorcosta/gh-lazy/pkg/github/fake"
+	"github.com/igorcosta/gh-lazy/pkg/state"
+)
+
+const projectURL = "https://github.com/users/me/projects/1"
+
+// assertCreated checks that gh holds one project, linked to me/api, with
+// both issues of tasksYAML in the Beta milestone and on the board.
+func assertCreated(t *testing.T, gh *fake.GitHub) {
+	t.Helper()
+	ctx := context.Background()
+
+	projects, err := gh.ListUserProjects(ctx, "me")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(projects) != 1 || projects[0].URL != projectURL {
+		t.Fatalf("projects = %+v, want only %s", projects, projectURL)
+	}
+	if linked := gh.LinkedRepos("me", "1"); len(linked) != 1 || linked[0] != "me/api" {
+		t.Errorf("linked repositories = %v, want [me/api]", linked)
+	}
+
+	milestones := gh.Milestones("me/api")
+	if len(milestones) != 1 || milestones[0].Title != "Beta" {
+		t.Fatalf("milestones = %+v, want only Beta", milestones)
+	}
+	issues := gh.Issues("me/api")
+	if len(issues) != 2 {
+		t.Fatalf("got %d issues, want 2", len(issues))
+	}
+	for _, issue := range issues {
+		if issue.Milestone == nil || issue.Milestone.Number != milestones[0].Number {
+			t.Errorf("issue %q is not in milestone Beta", issue.Title)
+		}
+	}
+	items, err := gh.ListProjectIssues(ctx, "me", "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 {
+		t.Errorf("got %d project items, want 2", len(items))
+	}
+}
+
+func assertStateCompleted(t *testing.T, stateFile string, want bool) {
+	t.Helper()
+	st, err := state.Load(stateFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st == nil {
+		t.Fatalf("no state file at %s", stateFile)
+	}
+	if st.Completed != want {
+		t.Errorf("state completed = %v, want %v", st.Completed, want)
+	}
+}
+
+func TestCreate(t *testing.T) {
+	gh := newFake(t)
+	stateFile := filepath.Join(t.TempDir(), "state.json")
+
+	out, err := run(t, gh, "create", "--repo", "me/api", "--tasks", writeTasks(t), "--state-file", stateFile)
+	if err != nil {
+		t.Fatalf("create: %v\n%s", err, out)
+	}
+	assertCreated(t, gh)
+	assertStateCompleted(t, stateFile, true)
+	assertContains(t, out, "Completed tasks: 5", "Skipped tasks: 0", "Failed tasks: 0")
+	if n := gh.Labels("me/api"); len(n) != 1 || n[0].Name != "docs" {
+		t.Errorf("labels = %+v, want docs", n)
+	}
+}
+
+func TestCreateWaitsOutRateLimits(t *testing.T) {
+	gh := newFake(t)
+	gh.Inject(fake.Fault{Op: "CreateIssue", RateLimitWait: time.Minute, Times: 1})
+	gh.Inject(fake.Fault{Op: "AddIssueToProject", RateLimitWait: 2 * time.Minute, Times: 1})
+	recorder := &rateLimitRecorder{GitHub: gh}
+	stateFile := filepath.Join(t.TempDir(), "state.json")
+
+	out, err := run(t, recorder, "create", "--repo", "me/api", "--tasks", writeTasks(t), "--state-file", stateFile)
+	if err != nil {
+		t.Fatalf("create: %v\n%s", err, out)
+	}
+	assertCreated(t, gh)
+	assertStateCompleted(t, stateFile, true)
+
+	waits := map[time.Duration]int{}
+	for _, wait := range recorder.waits {
+		waits[wait]++
+	}
+	if waits[time.Minute] != 1 || waits[2*time.Minute] != 1 || waits[0] != 2 {
+		t.Errorf("rate limit waits = %v, want 1m and 2m, each followed by 0", recorder.waits)
+	}
+}
+
+func TestCreateResumesAfterFailedCreateIssue(t *testing.T) {
+	gh := newFake(t)
+	gh.Inject(fake.Fault{Op: "CreateIssue", After: 1, Times: 1, Err: fake.Error(github.KindOther, "create issue", "server error")})
+	tasks := writeTasks(t)
+	stateFile := filepath.Join(t.TempDir(), "state.json")
+	args := []string{"create", "--repo", "me/api", "--tasks", tasks, "--state-file", stateFile}
+
+	out, err := run(t, gh, args...)
+	if err != nil {
+		t.Fatalf("create: %v\n%s", err, out)
+	}
+	assertContains(t, out, "Failed tasks: 1", "--resume")
+	assertStateCompleted(t, stateFile, false)
+	if issues := gh.Issues("me/api"); len(issues) != 1 {
+		t.Fatalf("got %d issues, want 1", len(issues))
+	}
+
+	if _, err := run(t, gh, args...); err == nil || !strings.Contains(err.Error(), "did not finish") {
+		t.Fatalf("create over an unfinished run: err = %v, want it refused", err)
+	}
+
+	out, err = run(t, gh, append(args, "--resume")...)
+	if err != nil {
+		t.Fatalf("create --resume: %v\n%s", err, out)
+	}
+	assertContains(t, out, "Resuming with project "+projectURL)
+	assertCreated(t, gh)
+	assertStateCompleted(t, stateFile, true)
+	if n := gh.Calls("CreateProject"); n != 1 {
+		t.Errorf("CreateProject called %d times, want 1", n)
+	}
+}
+
+func TestCreateResumesAfterFailedAddIssueToProject(t *testing.T) {
+	gh := newFake(t)
+	gh.Inject(fake.Fault{Op: "AddIssueToProject", Times: 1, Err: fake.Error(github.KindValidation, "add issue to project", "rejected")})
+	tasks := writeTasks(t)
+	stateFile := filepath.Join(t.TempDir(), "state.json")
+	args := []string{"create", "--repo", "me/api", "--tasks", tasks, "--state-file", stateFile}
+
+	out, err := run(t, gh, args...)
+	if err != nil {
+		t.Fatalf("create: %v\n%s", err, out)
+	}
+	assertContains(t, out, "Completed tasks: 4", "Skipped tasks: 1", "Failed tasks: 0", "--resume")
+	assertStateCompleted(t, stateFile, false)
+
+	if _, err := run(t, gh, args...); err == nil || !strings.Contains(err.Error(), "did not finish") {
+		t.Fatalf("create over an unfinished run: err = %v, want it refused", err)
+	}
+	if n := gh.Calls("CreateProject"); n != 1 {
+		t.Fatalf("CreateProject called %d times, want 1", n)
+	}
+
+	out, err = run(t, gh, append(args, "--resume")...)
+	if err != nil {
+		t.Fatalf("create --resume: %v\n%s", err, out)
+	}
+	assertCreated(t, gh)
+	assertStateCompleted(t, stateFile, true)
+	if n := gh.Calls("CreateIssue"); n != 2 {
+		t.Errorf("CreateIssue called %d times, want 2", n)
+	}
+}
+
+func TestCreateOnExistingBoardCountsEachIssueOnce(t *testing.T) {
+	gh := newFake(t)
+	tasks := writeTasks(t)
+	dir := t.TempDir()
+
+	if out, err := run(t, gh, "create", "--repo", "me/api", "--tasks", tasks, "--state-file", filepath.Join(dir, "first.json")); err != nil {
+		t.Fatalf("create: %v\n%s", err, out)
+	}
+
+	out, err := run(t, gh, "create", "--repo", "me/api", "--tasks", tasks, "--project", projectURL, "--state-file", filepath.Join(dir, "second.json"))
+	if err != nil {
+		t.Fatalf("create --project: %v\n%s", err, out)
+	}
+	assertContains(t, out, "Adding to existing project "+projectURL, "Completed tasks: 3", "Skipped tasks: 2")
+	if strings.Contains(out, "Resuming") {
+		t.Errorf("create --project claims to resume:\n%s", out)
+	}
+	assertCreated(t, gh)
+	assertStateCompleted(t, filepath.Join(dir, "second.json"), true)
+}
+
+func TestCreatePlan(t *testing.T) {
+	gh := newFake(t)
+	tasks := writeTasks(t)
+	dir := t.TempDir()
+
+	out, err := run(t, gh, "create", "--plan", "--repo", "me/api", "--tasks", tasks, "--state-file", filepath.Join(dir, "state.json"))
+	var exitErr *ExitError
+	if !errors.As(err, &exitErr) || exitErr.Code != 2 {
+		t.Fatalf("create --plan on an empty repository: err = %v, want exit code 2\n%s", err, out)
+	}
+	if n := gh.Calls("CreateProject") + gh.Calls("CreateIssue") + gh.Calls("CreateMilestone"); n != 0 {
+		t.Fatalf("create --plan made %d changes", n)
+	}
+
+	if out, err := run(t, gh, "create", "--repo", "me/api", "--tasks", tasks, "--state-file", filepath.Join(dir, "state.json")); err != nil {
+		t.Fatalf("create: %v\n%s", err, out)
+	}
+
+	out, err = run(t, gh, "create", "--plan", "--repo", "me/api", "--tasks", tasks, "--project", projectURL, "--state-file", filepath.Join(dir, "plan.json"))
+	if err != nil {
+		t.Fatalf("create --plan in sync: err = %v, want nil\n%s", err, out)
+	}
+}
+
+func TestCreatePlanUsesProjectFromStateFile(t *testing.T) {
+	gh := newFake(t)
+	gh.Inject(fake.Fault{Op: "AddIssueToProject", Times: 1, Err: fake.Error(github.KindValidation, "add issue to project", "rejected")})
+	tasks := writeTasks(t)
+	stateFile := filepath.Join(t.TempDir(), "state.json")
+	args := []string{"create", "--repo", "me/api", "--tasks", tasks, "--state-file", stateFile}
+
+	if out, err := run(t, gh, args...); err != nil {
+		t.Fatalf("create: %v\n%s", err, out)
+	}
+
+	out, err := run(t, gh, append(args, "--plan", "--resume")...)
+	var exitErr *ExitError
+	if !errors.As(err, &exitErr) || exitErr.Code != 2 || !strings.Contains(err.Error(), "1 changes pending") {
+		t.Fatalf("create --plan --resume with one issue off the board: err = %v, want 1 change pending\n%s", err, out)
+	}
+
+	if out, err := run(t, gh, append(args, "--resume")...); err != nil {
+		t.Fatalf("create --resume: %v\n%s", err, out)
+	}
+	if out, err := run(t, gh, append(args, "--plan", "--resume")...); err != nil {
+		t.Fatalf("create --plan --resume in sync: err = %v, want nil\n%s", err, out)
+	}
+	if n := gh.Calls("CreateProject"); n != 1 {
+		t.Errorf("CreateProject called %d times, want 1", n)
+	}
+}
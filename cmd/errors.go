@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/igorcosta/gh-lazy/pkg/github"
+)
+
+// remediation returns advice on fixing an error GitHub returned, or "" if
+// there is none to give.
+func remediation(err error, host string) string {
+	e := github.AsError(err)
+	if e == nil {
+		return ""
+	}
+
+	switch e.Kind {
+	case github.KindForbidden:
+		if len(e.MissingScopes) > 0 {
+			// GitHub lists the narrowest scope first; suggest the broadest,
+			// which also covers writes.
+			return fmt.Sprintf("Your token is missing the %s scope. Run 'gh auth refresh --hostname %s --scopes %s', or add the scope to your token.",
+				strings.Join(e.MissingScopes, " or "), host, e.MissingScopes[len(e.MissingScopes)-1])
+		}
+		return "Your token isn't allowed to do this. Check that it has the repo and project scopes and access to the repository."
+	case github.KindNotFound:
+		return "Check the name, and that your token can see it: GitHub reports resources a token can't access as not found."
+	case github.KindRateLimited:
+		return "The rate limit is still used up. Wait for it to reset, then run the command again."
+	}
+	return ""
+}
+
+// withRemediation appends remediation advice, if there is any, to err.
+func withRemediation(err error, host string) error {
+	if hint := remediation(err, host); hint != "" {
+		return fmt.Errorf("%w\n💡 %s", err, hint)
+	}
+	return err
+}
+
+// checkScopes fails if the token lacks any of the given OAuth scopes. Tokens
+// whose scopes GitHub doesn't report are let through; GitHub will refuse
+// what they can't do soon enough.
+func checkScopes(ctx context.Context, client github.API, required ...string) error {
+	granted, known, err := client.TokenScopes(ctx)
+	if err != nil {
+		return withRemediation(err, client.Host())
+	}
+	if !known {
+		return nil
+	}
+
+	has := map[string]bool{}
+	for _, scope := range granted {
+		has[scope] = true
+	}
+	var missing []string
+	for _, scope := range required {
+		if !has[scope] {
+			missing = append(missing, scope)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	return fmt.Errorf("your token is missing the %s scope\n💡 Run 'gh auth refresh --hostname %s --scopes %s', or add the scope to your token.",
+		strings.Join(missing, ", "), client.Host(), strings.Join(missing, ","))
+}
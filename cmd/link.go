@@ -1,58 +1,61 @@
 package cmd
 
 import (
-	"context"
 	"fmt"
 	"time"
 
-	"github.com/igorcosta/gh-lazy/pkg/config"
 	"github.com/igorcosta/gh-lazy/pkg/github"
 	"github.com/igorcosta/gh-lazy/pkg/utils"
 	"github.com/spf13/cobra"
 )
 
 var linkCmd = &cobra.Command{
-	Use:   "link",
-	Short: "Link project to repository",
+	Use:         "link",
+	Short:       "Link project to repository",
+	Annotations: map[string]string{githubAnnotation: "true"},
 	RunE: func(cmd *cobra.Command, args []string) error {
-		cfg, err := config.LoadConfig()
-		if err != nil {
-			return utils.WrapError(err, "failed to load config")
-		}
+		cfg, client := session.cfg, session.client
 
-		token, err := utils.ReadTokenFromFile(cfg.TokenFile)
-		if err != nil {
-			return utils.WrapError(err, "failed to read token")
+		projectIDOrURL := stringFlag(cmd, "project", cfg.Project)
+		if projectIDOrURL == "" {
+			return fmt.Errorf("project is required. Use -p or --project flag to specify it")
 		}
 
-		client, err := github.NewClient(token)
+		projectNumber, err := utils.ParseProjectID(projectIDOrURL)
 		if err != nil {
-			return utils.WrapError(err, "failed to create GitHub client")
+			return utils.WrapError(err, "failed to parse project ID")
 		}
 
-		projectNumber, err := cmd.Flags().GetString("project")
-		if err != nil {
-			return utils.WrapError(err, "failed to get project number")
-		}
-
-		repoName, err := cmd.Flags().GetString("repo")
-		if err != nil {
-			return utils.WrapError(err, "failed to get repository name")
-		}
+		owner, _ := cmd.Flags().GetString("owner")
 
+		repoName := stringFlag(cmd, "repo", cfg.Repo)
 		if repoName == "" {
 			return fmt.Errorf("repository name is required. Use -r or --repo flag to specify the name")
 		}
 
-		_, repo, err := splitRepoName(repoName)
+		repoOwner, _, err := splitRepoName(repoName)
 		if err != nil {
 			return utils.WrapError(err, "invalid repository name")
 		}
 
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+		ctx, cancel := commandContext(cmd, cfg, 5*time.Minute)
 		defer cancel()
 
-		err = client.LinkProjectToRepo(ctx, repo, projectNumber)
+		if err := checkScopes(ctx, client, "repo", "project"); err != nil {
+			return err
+		}
+
+		if owner == "" {
+			owner = github.ProjectOwnerFromURL(projectIDOrURL)
+		}
+		if owner == "" {
+			owner = cfg.Owner
+		}
+		if owner == "" {
+			owner = repoOwner
+		}
+
+		err = client.LinkProjectToRepo(ctx, owner, projectNumber, repoName)
 		if err != nil {
 			return utils.WrapError(err, "failed to link project to repository")
 		}
@@ -64,8 +67,7 @@ var linkCmd = &cobra.Command{
 
 func init() {
 	rootCmd.AddCommand(linkCmd)
-	linkCmd.Flags().StringP("project", "p", "", "Project number to link")
+	linkCmd.Flags().StringP("project", "p", "", "Project number or URL to link")
+	linkCmd.Flags().String("owner", "", "User or organization that owns the project (default: taken from the project URL, or the repository owner)")
 	linkCmd.Flags().StringP("repo", "r", "", "The repository name (e.g., 'username/repo')")
-	linkCmd.MarkFlagRequired("project")
-	linkCmd.MarkFlagRequired("repo")
 }
@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/igorcosta/gh-lazy/pkg/github"
+	"github.com/igorcosta/gh-lazy/pkg/github/fake"
+)
+
+func TestLink(t *testing.T) {
+	gh := newFake(t)
+	gh.AddOrg("acme")
+	gh.AddRepo("acme/web")
+	url, err := gh.CreateProject(context.Background(), "acme", "Roadmap")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if out, err := run(t, gh, "link", "--project", url, "--repo", "acme/web"); err != nil {
+		t.Fatalf("link: %v\n%s", err, out)
+	}
+	if linked := gh.LinkedRepos("acme", "1"); len(linked) != 1 || linked[0] != "acme/web" {
+		t.Errorf("linked repositories = %v, want [acme/web]", linked)
+	}
+}
+
+func TestLinkReportsForbidden(t *testing.T) {
+	gh := newFake(t)
+	if _, err := gh.CreateProject(context.Background(), "", "Roadmap"); err != nil {
+		t.Fatal(err)
+	}
+	gh.Inject(fake.Fault{Op: "LinkProjectToRepo", Err: fake.Error(github.KindForbidden, "link project to repository", "resource not accessible")})
+
+	_, err := run(t, gh, "link", "--project", "1", "--repo", "me/api")
+	if !errors.Is(err, github.ErrForbidden) {
+		t.Fatalf("link: err = %v, want ErrForbidden", err)
+	}
+	if linked := gh.LinkedRepos("me", "1"); len(linked) != 0 {
+		t.Errorf("linked repositories = %v, want none", linked)
+	}
+}
@@ -1,14 +1,14 @@
 package cmd
 
 import (
-	"context"
+	"errors"
 	"fmt"
+	"os"
 	"os/exec"
 	"strings"
 	"time"
 
 	"github.com/fatih/color"
-	"github.com/igorcosta/gh-lazy/pkg/config"
 	"github.com/igorcosta/gh-lazy/pkg/github"
 	"github.com/igorcosta/gh-lazy/pkg/utils"
 	"github.com/manifoldco/promptui"
@@ -16,8 +16,9 @@ import (
 	"github.com/spf13/cobra"
 )
 
-func getCurrentRepo() (string, error) {
+func getCurrentRepo(host string) (string, error) {
 	cmd := exec.Command("gh", "repo", "view", "--json", "nameWithOwner", "--jq", ".nameWithOwner")
+	cmd.Env = append(os.Environ(), "GH_HOST="+host)
 	output, err := cmd.Output()
 	if err != nil {
 		return "", fmt.Errorf("failed to get current repository: %w", err)
@@ -31,40 +32,38 @@ var nukeCmd = &cobra.Command{
 	Long: `Delete a GitHub project and optionally all issues linked to it.
 
 **Warning:** This operation is irreversible. Use with caution.`,
+	Annotations: map[string]string{githubAnnotation: "true"},
 	RunE: func(cmd *cobra.Command, args []string) error {
-		cfg, err := config.LoadConfig()
-		if err != nil {
-			return fmt.Errorf("failed to load config: %w", err)
-		}
+		cfg, client := session.cfg, session.client
 
+		// Never the default project from the config: deleting the team's board
+		// should take an explicit --projectid or a confirmed pick.
 		projectIDOrURL, _ := cmd.Flags().GetString("projectid")
 		deleteAll, _ := cmd.Flags().GetBool("all")
 		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		owner, _ := cmd.Flags().GetString("owner")
 
-		repoName, err := getCurrentRepo()
-		if err != nil {
-			return fmt.Errorf("failed to get current repository: %w", err)
+		repoName := stringFlag(cmd, "repo", cfg.Repo)
+		if repoName == "" {
+			var err error
+			repoName, err = getCurrentRepo(cfg.Host())
+			if err != nil {
+				return fmt.Errorf("failed to get current repository: %w", err)
+			}
 		}
 		fmt.Printf("Current repository: %s\n", repoName)
 
-		token, err := utils.GetToken(cfg.TokenFile)
-		if err != nil {
-			utils.PrintUserGuide()
-			return fmt.Errorf("authentication error: %w", err)
-		}
-
-		client, err := github.NewClient(token)
-		if err != nil {
-			return fmt.Errorf("failed to create GitHub client: %w", err)
-		}
-
-		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+		ctx, cancel := commandContext(cmd, cfg, 30*time.Minute)
 		defer cancel()
 
 		if projectIDOrURL == "" {
-			projects, err := client.ListUserProjects(ctx)
+			listOwner := stringFlag(cmd, "owner", cfg.Owner)
+			if listOwner == "" {
+				listOwner = appOwner(cfg, repoName)
+			}
+			projects, err := client.ListUserProjects(ctx, listOwner)
 			if err != nil {
-				return fmt.Errorf("failed to list projects: %w", err)
+				return withRemediation(fmt.Errorf("failed to list projects: %w", err), client.Host())
 			}
 
 			if len(projects) == 0 {
@@ -122,9 +121,34 @@ var nukeCmd = &cobra.Command{
 			return fmt.Errorf("failed to parse project ID: %w", err)
 		}
 
-		issues, err := client.ListProjectIssues(ctx, projectNumber)
+		// Deleting issues needs delete_repo on top of the project scope.
+		scopes := []string{"project"}
+		if deleteAll && !dryRun {
+			scopes = append(scopes, "repo", "delete_repo")
+		}
+		if err := checkScopes(ctx, client, scopes...); err != nil {
+			return err
+		}
+
+		if owner == "" {
+			owner = github.ProjectOwnerFromURL(projectIDOrURL)
+		}
+		if owner == "" {
+			owner = cfg.Owner
+		}
+		if owner == "" {
+			owner = appOwner(cfg, repoName)
+		}
+		if owner == "" {
+			owner, err = client.GetProjectOwner(ctx, projectIDOrURL)
+			if err != nil {
+				return fmt.Errorf("failed to get project owner: %w", err)
+			}
+		}
+
+		issues, err := client.ListProjectIssues(ctx, owner, projectNumber)
 		if err != nil {
-			return fmt.Errorf("failed to list issues linked to the project: %w", err)
+			return withRemediation(fmt.Errorf("failed to list issues linked to the project: %w", err), client.Host())
 		}
 
 		totalTasks := 1 // For deleting the project
@@ -145,6 +169,8 @@ var nukeCmd = &cobra.Command{
 				BarEnd:        "]",
 			}))
 
+		showRateLimitWaits(client, bar)
+
 		if dryRun {
 			color.Yellow("** Dry Run Mode Enabled **")
 			color.Yellow("No actual deletions will occur.")
@@ -154,26 +180,40 @@ var nukeCmd = &cobra.Command{
 		failed := 0
 		deleted := 0
 		skipped := 0
+		deleteHintShown := false
 
 		if deleteAll {
 			color.Cyan("Deleting issues associated with the project:")
 			for _, issue := range issues {
+				if ctx.Err() != nil {
+					break
+				}
 				if dryRun {
 					color.Cyan("🗒️ Would delete issue #%d: %s (Repository: %s)", issue.Number, issue.Title, issue.Repository)
 					bar.Add(1)
 				} else {
 					fmt.Printf("Deleting issue #%d: %s (Repository: %s)\n", issue.Number, issue.Title, issue.Repository)
 					err := client.DeleteIssue(ctx, issue.Repository, issue.Number)
-					if err != nil {
+					switch {
+					case errors.Is(err, github.ErrNotFound):
+						color.Yellow("⏭️ Issue #%d is already gone", issue.Number)
+						skipped++
+					case errors.Is(err, github.ErrForbidden):
+						color.Red("❌ Failed to delete issue #%d: %v", issue.Number, err)
+						if !deleteHintShown {
+							color.Yellow("💡 Deleting issues needs admin access to %s; without it, run nuke without --all.", issue.Repository)
+							deleteHintShown = true
+						}
+						failed++
+					case err != nil:
 						color.Red("❌ Failed to delete issue #%d: %v", issue.Number, err)
 						failed++
-					} else {
+					default:
 						color.Green("🗑️ Deleted issue #%d: %s", issue.Number, issue.Title)
 						deleted++
 					}
 					bar.Add(1)
 				}
-				time.Sleep(time.Second) // Small delay to avoid overwhelming the API
 			}
 		} else {
 			skipped = len(issues)
@@ -182,14 +222,17 @@ var nukeCmd = &cobra.Command{
 			}
 		}
 
-		if dryRun {
+		stopped := interruption(ctx)
+		if stopped != nil {
+			color.Yellow("⚠️ Stopped early (%v); the project was not deleted", stopped)
+		} else if dryRun {
 			color.Cyan("🗒️ Would delete project %s", projectNumber)
 			bar.Add(1)
 		} else {
 			fmt.Printf("Deleting project %s\n", projectNumber)
-			err = client.DeleteProject(ctx, projectNumber)
+			err = client.DeleteProject(ctx, owner, projectNumber)
 			if err != nil {
-				color.Red("❌ Failed to delete project: %v", err)
+				color.Red("❌ Failed to delete project: %v", withRemediation(err, client.Host()))
 				failed++
 			} else {
 				bar.Add(1)
@@ -206,6 +249,9 @@ var nukeCmd = &cobra.Command{
 				color.Green("  🗒️ Issues that would be deleted: %d", len(issues))
 			} else {
 				color.Green("  🗑️ Deleted issues: %d", deleted)
+				if skipped > 0 {
+					color.Yellow("  ⏭️ Already gone: %d", skipped)
+				}
 				if failed > 0 {
 					color.Red("  ❌ Failed deletions: %d", failed)
 				}
@@ -213,6 +259,9 @@ var nukeCmd = &cobra.Command{
 		} else {
 			color.Yellow("  ⏭️ Skipped issues: %d", skipped)
 		}
+		if stopped != nil {
+			return fmt.Errorf("%w before finishing; run nuke again to delete what's left", stopped)
+		}
 		if dryRun {
 			color.Green("  🗒️ Project that would be deleted: %s", projectNumber)
 		} else {
@@ -228,4 +277,5 @@ func init() {
 	nukeCmd.Flags().StringP("projectid", "p", "", "Project ID or URL to nuke")
 	nukeCmd.Flags().BoolP("all", "a", false, "Delete all issues linked to the project")
 	nukeCmd.Flags().Bool("dry-run", false, "Show what would happen without making changes")
+	nukeCmd.Flags().String("owner", "", "User or organization that owns the project (default: taken from the project URL, or you; the repository owner for a GitHub App)")
 }
@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/igorcosta/gh-lazy/pkg/github"
+	"github.com/igorcosta/gh-lazy/pkg/github/fake"
+)
+
+// newCreatedFake returns a fake on which create has made tasksYAML's project.
+func newCreatedFake(t *testing.T) *fake.GitHub {
+	t.Helper()
+	gh := newFake(t)
+	stateFile := filepath.Join(t.TempDir(), "state.json")
+	if out, err := run(t, gh, "create", "--repo", "me/api", "--tasks", writeTasks(t), "--state-file", stateFile); err != nil {
+		t.Fatalf("create: %v\n%s", err, out)
+	}
+	return gh
+}
+
+func projectCount(t *testing.T, gh *fake.GitHub) int {
+	t.Helper()
+	projects, err := gh.ListUserProjects(context.Background(), "me")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return len(projects)
+}
+
+func TestNukeAll(t *testing.T) {
+	gh := newCreatedFake(t)
+
+	out, err := run(t, gh, "nuke", "--projectid", projectURL, "--all", "--repo", "me/api")
+	if err != nil {
+		t.Fatalf("nuke: %v\n%s", err, out)
+	}
+	assertContains(t, out, "Deleted issues: 2", "Deleted project: 1")
+	if n := projectCount(t, gh); n != 0 {
+		t.Errorf("%d projects left, want 0", n)
+	}
+	if issues := gh.Issues("me/api"); len(issues) != 0 {
+		t.Errorf("%d issues left, want 0", len(issues))
+	}
+}
+
+func TestNukeDryRun(t *testing.T) {
+	gh := newCreatedFake(t)
+
+	out, err := run(t, gh, "nuke", "--projectid", projectURL, "--all", "--dry-run", "--repo", "me/api")
+	if err != nil {
+		t.Fatalf("nuke --dry-run: %v\n%s", err, out)
+	}
+	assertContains(t, out, "Issues that would be deleted: 2")
+	if n := gh.Calls("DeleteIssue") + gh.Calls("DeleteProject"); n != 0 {
+		t.Errorf("nuke --dry-run deleted %d things", n)
+	}
+	assertCreated(t, gh)
+}
+
+func TestNukeKeepsGoingWhenAnIssueCantBeDeleted(t *testing.T) {
+	gh := newCreatedFake(t)
+	gh.Inject(fake.Fault{Op: "DeleteIssue", Times: 1, Err: fake.Error(github.KindForbidden, "delete issue", "must have admin rights")})
+
+	out, err := run(t, gh, "nuke", "--projectid", projectURL, "--all", "--repo", "me/api")
+	if err != nil {
+		t.Fatalf("nuke: %v\n%s", err, out)
+	}
+	assertContains(t, out, "Deleted issues: 1", "Failed deletions: 1", "needs admin access")
+	if issues := gh.Issues("me/api"); len(issues) != 1 {
+		t.Errorf("%d issues left, want 1", len(issues))
+	}
+	if n := projectCount(t, gh); n != 0 {
+		t.Errorf("%d projects left, want 0", n)
+	}
+}
+
+func TestNukeIgnoresDefaultProject(t *testing.T) {
+	gh := newCreatedFake(t)
+	t.Setenv("LAZY_PROJECT", projectURL)
+	// With no terminal to answer it, the project picker fails.
+	stdin, err := os.Open(os.DevNull)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stdin.Close()
+	oldStdin := os.Stdin
+	os.Stdin = stdin
+	defer func() { os.Stdin = oldStdin }()
+
+	if _, err := run(t, gh, "nuke", "--all", "--repo", "me/api"); err == nil {
+		t.Fatal("nuke without --projectid: err = nil, want the picker to fail")
+	}
+	if n := gh.Calls("DeleteIssue") + gh.Calls("DeleteProject"); n != 0 {
+		t.Errorf("nuke deleted %d things without --projectid", n)
+	}
+	assertCreated(t, gh)
+}
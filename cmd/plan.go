@@ -0,0 +1,333 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/igorcosta/gh-lazy/pkg/github"
+	"github.com/igorcosta/gh-lazy/pkg/models"
+)
+
+type planAction string
+
+const (
+	actionCreate   planAction = "create"
+	actionExists   planAction = "exists"
+	actionUpdate   planAction = "would-update"
+	actionLink     planAction = "would-link"
+	actionDrift    planAction = "differs"
+	actionClose    planAction = "close"
+	planDateLayout            = "2006-01-02"
+)
+
+// fieldChange is a single field that differs between the tasks file and GitHub.
+type fieldChange struct {
+	Field string
+	From  string
+	To    string
+	// ApplyOnly marks a field that create leaves alone and only apply changes.
+	ApplyOnly bool
+}
+
+type planChange struct {
+	Action  planAction
+	Kind    string
+	Name    string
+	Fields  []fieldChange
+	Comment string
+}
+
+type createPlan struct {
+	Changes []planChange
+}
+
+func (p *createPlan) add(action planAction, kind, name, comment string, fields ...fieldChange) {
+	p.Changes = append(p.Changes, planChange{Action: action, Kind: kind, Name: name, Fields: fields, Comment: comment})
+}
+
+// Pending returns how many changes create would make. Drift that only apply
+// fixes doesn't count, since running create wouldn't clear it.
+func (p *createPlan) Pending() int {
+	pending := 0
+	for _, c := range p.Changes {
+		if c.Action != actionExists && c.Action != actionDrift {
+			pending++
+		}
+	}
+	return pending
+}
+
+// buildCreatePlan resolves every label, milestone and issue in the tasks file
+// against the repository without writing anything.
+func buildCreatePlan(ctx context.Context, client github.API, idx *github.RepoIndex, repoName string, tasks *models.TasksFile, existingProject *models.Project) (*createPlan, error) {
+	plan := &createPlan{}
+
+	projectTitle := tasks.ProjectTitle
+	linked := false
+	if existingProject != nil {
+		projectTitle = existingProject.Title
+		plan.add(actionExists, "project", projectTitle, fmt.Sprintf("#%d", existingProject.Number))
+
+		projectOwner, err := client.GetProjectOwner(ctx, existingProject.URL)
+		if err != nil {
+			return nil, err
+		}
+		projectNumber := fmt.Sprintf("%d", existingProject.Number)
+		if err := github.LoadProjectItems(ctx, client, idx, projectOwner, projectNumber); err != nil {
+			return nil, err
+		}
+		repos, err := client.ListProjectRepos(ctx, projectOwner, projectNumber)
+		if err != nil {
+			return nil, err
+		}
+		for _, repo := range repos {
+			linked = linked || strings.EqualFold(repo, repoName)
+		}
+	} else {
+		plan.add(actionCreate, "project", projectTitle, "")
+	}
+	if !linked {
+		plan.add(actionLink, "project", projectTitle, "to "+repoName)
+	}
+
+	for _, label := range wantedLabels(tasks) {
+		if idx.HasLabel(label.Name) {
+			plan.add(actionExists, "label", label.Name, "")
+		} else {
+			plan.add(actionCreate, "label", label.Name, "")
+		}
+	}
+
+	for _, milestone := range tasks.Milestones {
+		existingMilestone := idx.FindMilestone(milestone.Milestone)
+		target := milestone.Milestone
+		if existingMilestone == nil {
+			plan.add(actionCreate, "milestone", milestone.Title, "")
+		} else {
+			target.Number = existingMilestone.Number
+			addResolved(plan, "milestone", milestone.Title, fmt.Sprintf("#%d", existingMilestone.Number),
+				milestoneChanges(*existingMilestone, milestone.Milestone))
+		}
+
+		for _, issue := range milestone.Issues {
+			existingIssue := idx.FindIssue(issue)
+			if existingIssue == nil {
+				plan.add(actionCreate, "issue", issue.Title, "in milestone "+milestone.Title)
+			} else {
+				addResolved(plan, "issue", issue.Title, fmt.Sprintf("#%d", existingIssue.Number),
+					issueChanges(*existingIssue, issue, target), "milestone")
+			}
+			onBoard := false
+			if existingIssue != nil {
+				_, onBoard = idx.ProjectItem(repoName, existingIssue.Number)
+			}
+			if !onBoard {
+				plan.add(actionLink, "issue", issue.Title, "to project "+projectTitle)
+			}
+		}
+	}
+
+	return plan, nil
+}
+
+// addResolved adds an existing milestone or issue to the plan. create only
+// changes the fields named in createFields; the rest are drift for apply.
+func addResolved(plan *createPlan, kind, name, comment string, fields []fieldChange, createFields ...string) {
+	if len(fields) == 0 {
+		plan.add(actionExists, kind, name, comment)
+		return
+	}
+	action := actionDrift
+	for i := range fields {
+		fields[i].ApplyOnly = true
+		for _, field := range createFields {
+			if fields[i].Field == field {
+				fields[i].ApplyOnly = false
+				action = actionUpdate
+			}
+		}
+	}
+	plan.add(action, kind, name, comment, fields...)
+}
+
+func milestoneChanges(existing, desired models.Milestone) []fieldChange {
+	var changes []fieldChange
+	if existing.Title != desired.Title {
+		changes = append(changes, fieldChange{Field: "title", From: existing.Title, To: desired.Title})
+	}
+	if from, to := stripMarker(existing.Description), stripMarker(desired.Description); from != to {
+		changes = append(changes, fieldChange{Field: "description", From: summarize(from), To: summarize(to)})
+	}
+	if !desired.DueOn.IsZero() && formatDate(existing.DueOn.Time) != formatDate(desired.DueOn.Time) {
+		changes = append(changes, fieldChange{Field: "due_on", From: formatDate(existing.DueOn.Time), To: formatDate(desired.DueOn.Time)})
+	}
+	if desired.Key != "" && existing.Key != desired.Key {
+		changes = append(changes, fieldChange{Field: "key", From: keyOrNone(existing.Key), To: desired.Key})
+	}
+	if desired.State != "" && existing.State != desired.State {
+		changes = append(changes, fieldChange{Field: "state", From: existing.State, To: desired.State})
+	}
+	return changes
+}
+
+// issueChanges compares an existing issue with the tasks file. Labels and
+// assignees are only ever added, so only missing ones count as drift.
+func issueChanges(existing models.RepoIssue, desired models.Issue, milestone models.Milestone) []fieldChange {
+	var changes []fieldChange
+	if existing.Title != desired.Title {
+		changes = append(changes, fieldChange{Field: "title", From: existing.Title, To: desired.Title})
+	}
+	if from, to := stripMarker(existing.Body), stripMarker(desired.Body); from != to {
+		changes = append(changes, fieldChange{Field: "body", From: summarize(from), To: summarize(to)})
+	}
+	if existingKey := github.ExtractKey(existing.Body); desired.Key != "" && existingKey != desired.Key {
+		changes = append(changes, fieldChange{Field: "key", From: keyOrNone(existingKey), To: desired.Key})
+	}
+	from := "none"
+	if existing.Milestone != nil {
+		from = existing.Milestone.Title
+	}
+	if milestone.Number == 0 || existing.Milestone == nil || existing.Milestone.Number != milestone.Number {
+		changes = append(changes, fieldChange{Field: "milestone", From: from, To: milestone.Title})
+	}
+
+	labels := labelNames(existing)
+	if merged := mergeNames(labels, desired.Labels); len(merged) != len(labels) {
+		changes = append(changes, fieldChange{Field: "labels", From: joinNames(labels), To: joinNames(merged)})
+	}
+
+	assignees := assigneeLogins(existing)
+	if merged := mergeNames(assignees, desired.Assignees); len(merged) != len(assignees) {
+		changes = append(changes, fieldChange{Field: "assignees", From: joinNames(assignees), To: joinNames(merged)})
+	}
+	return changes
+}
+
+func labelNames(issue models.RepoIssue) []string {
+	names := make([]string, len(issue.Labels))
+	for i, label := range issue.Labels {
+		names[i] = label.Name
+	}
+	return names
+}
+
+func assigneeLogins(issue models.RepoIssue) []string {
+	logins := make([]string, len(issue.Assignees))
+	for i, user := range issue.Assignees {
+		logins[i] = user.Login
+	}
+	return logins
+}
+
+// mergeNames returns existing plus any desired names it lacks, case-insensitively.
+func mergeNames(existing, desired []string) []string {
+	merged := append([]string{}, existing...)
+	seen := map[string]bool{}
+	for _, name := range existing {
+		seen[strings.ToLower(name)] = true
+	}
+	for _, name := range desired {
+		if !seen[strings.ToLower(name)] {
+			seen[strings.ToLower(name)] = true
+			merged = append(merged, name)
+		}
+	}
+	return merged
+}
+
+func joinNames(names []string) string {
+	if len(names) == 0 {
+		return "none"
+	}
+	sorted := append([]string{}, names...)
+	sort.Strings(sorted)
+	return strings.Join(sorted, ", ")
+}
+
+func keyOrNone(key string) string {
+	if key == "" {
+		return "none"
+	}
+	return key
+}
+
+func stripMarker(text string) string {
+	return strings.TrimSpace(github.WithKeyMarker(text, ""))
+}
+
+func summarize(text string) string {
+	if text == "" {
+		return "(empty)"
+	}
+	firstLine := strings.SplitN(text, "\n", 2)[0]
+	if runes := []rune(firstLine); len(runes) > 40 {
+		firstLine = string(runes[:40])
+	}
+	if firstLine != text {
+		firstLine += "…"
+	}
+	return fmt.Sprintf("%q", firstLine)
+}
+
+func formatDate(t time.Time) string {
+	if t.IsZero() {
+		return "none"
+	}
+	return t.UTC().Format(planDateLayout)
+}
+
+func printPlan(plan *createPlan) {
+	counts := map[planAction]int{}
+	for _, change := range plan.Changes {
+		counts[change.Action]++
+		printChange(change)
+	}
+
+	fmt.Println()
+	fmt.Printf("Plan: %d to create, %d to update, %d to link, %d unchanged.\n",
+		counts[actionCreate], counts[actionUpdate], counts[actionLink], counts[actionExists]+counts[actionDrift])
+	if hasApplyOnly(plan) {
+		color.Yellow("💡 create leaves fields marked (apply only) alone; run 'gh lazy apply' to update them.")
+	}
+}
+
+func hasApplyOnly(plan *createPlan) bool {
+	for _, change := range plan.Changes {
+		for _, field := range change.Fields {
+			if field.ApplyOnly {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func printChange(change planChange) {
+	line := fmt.Sprintf("%s %s %q", change.Kind, change.Action, change.Name)
+	if change.Comment != "" {
+		line += " (" + change.Comment + ")"
+	}
+	switch change.Action {
+	case actionCreate:
+		color.Green("  + %s", line)
+	case actionUpdate, actionDrift:
+		color.Yellow("  ~ %s", line)
+		for _, field := range change.Fields {
+			if field.ApplyOnly {
+				color.Yellow("      %s: %s → %s (apply only)", field.Field, field.From, field.To)
+			} else {
+				color.Yellow("      %s: %s → %s", field.Field, field.From, field.To)
+			}
+		}
+	case actionLink:
+		color.Cyan("  → %s", line)
+	case actionClose:
+		color.Red("  - %s", line)
+	default:
+		fmt.Printf("  = %s\n", line)
+	}
+}
@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/igorcosta/gh-lazy/pkg/github"
+	"github.com/schollz/progressbar/v3"
+)
+
+// showRateLimitWaits switches the progress bar description to a rate limit
+// notice while any call is waiting for the limit to reset.
+func showRateLimitWaits(client github.API, bar *progressbar.ProgressBar) {
+	var mu sync.Mutex
+	waiting := 0
+	description := bar.State().Description
+
+	client.OnRateLimit(func(wait time.Duration) {
+		mu.Lock()
+		defer mu.Unlock()
+		if wait > 0 {
+			if waiting == 0 {
+				description = bar.State().Description
+			}
+			waiting++
+			bar.Describe(fmt.Sprintf("[yellow]waiting for rate limit reset (%s)...[reset]", wait.Round(time.Second)))
+			return
+		}
+		waiting--
+		if waiting == 0 {
+			bar.Describe(description)
+		}
+	})
+}
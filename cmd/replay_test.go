@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// stubGitHub answers the REST and GraphQL calls create makes for tasksYAML on
+// the repository me/api, as GitHub would.
+type stubGitHub struct {
+	mu     sync.Mutex
+	issues int
+	items  int
+}
+
+func (s *stubGitHub) RoundTrip(req *http.Request) (*http.Response, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var body string
+	if req.Body != nil {
+		data, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		body = string(data)
+	}
+
+	status, answer := http.StatusOK, ""
+	switch route := req.Method + " " + req.URL.Path; {
+	case route == "GET /user":
+		answer = `{"login": "me"}`
+	case route == "GET /rate_limit":
+		answer = `{}`
+	case route == "GET /repos/me/api/milestones", route == "GET /repos/me/api/issues", route == "GET /repos/me/api/labels":
+		answer = `[]`
+	case route == "POST /repos/me/api/labels":
+		status, answer = http.StatusCreated, `{}`
+	case route == "POST /repos/me/api/milestones":
+		status, answer = http.StatusCreated, `{"number": 1}`
+	case route == "POST /repos/me/api/issues":
+		s.issues++
+		status, answer = http.StatusCreated, fmt.Sprintf(`{"number": %d}`, s.issues)
+	case strings.HasPrefix(route, "PATCH /repos/me/api/issues/"):
+		answer = `{}`
+	case route == "POST /graphql":
+		switch {
+		case strings.Contains(body, "repositoryOwner("):
+			answer = `{"data": {"repositoryOwner": {"id": "U_me"}}}`
+		case strings.Contains(body, "createProjectV2("):
+			answer = `{"data": {"createProjectV2": {"projectV2": {"id": "PVT_1", "number": 1, "title": "Launch", "url": "` + projectURL + `"}}}}`
+		case strings.Contains(body, "repository("):
+			answer = `{"data": {"repository": {"id": "R_api"}}}`
+		case strings.Contains(body, "linkProjectV2ToRepository("):
+			answer = `{"data": {"linkProjectV2ToRepository": {"clientMutationId": null}}}`
+		case strings.Contains(body, "resource("):
+			answer = `{"data": {"resource": {"id": "I_issue"}}}`
+		case strings.Contains(body, "addProjectV2ItemById("):
+			s.items++
+			answer = fmt.Sprintf(`{"data": {"addProjectV2ItemById": {"item": {"id": "PVTI_%d"}}}}`, s.items)
+		}
+	}
+	if answer == "" {
+		status, answer = http.StatusNotFound, `{"message": "Not Found"}`
+	}
+	return &http.Response{
+		StatusCode: status,
+		Status:     fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(answer)),
+		Request:    req,
+	}, nil
+}
+
+// offline fails every request, to show a replayed run never goes online.
+type offline struct{}
+
+func (offline) RoundTrip(req *http.Request) (*http.Response, error) {
+	return nil, fmt.Errorf("unexpected request to %s while replaying", req.URL)
+}
+
+func TestReplayRecordedCreate(t *testing.T) {
+	isolate(t)
+	tasks := writeTasks(t)
+	dir := t.TempDir()
+	cassette := filepath.Join(dir, "cassette")
+	oldTransport := http.DefaultTransport
+	t.Cleanup(func() { http.DefaultTransport = oldTransport })
+
+	http.DefaultTransport = &stubGitHub{}
+	recorded, err := run(t, nil, "create", "--repo", "me/api", "--tasks", tasks, "--concurrency", "1",
+		"--state-file", filepath.Join(dir, "record.json"), "--record", cassette)
+	if err != nil {
+		t.Fatalf("create --record: %v\n%s", err, recorded)
+	}
+	assertContains(t, recorded, "Completed tasks: 5")
+
+	http.DefaultTransport = offline{}
+	replayed, err := run(t, nil, "create", "--repo", "me/api", "--tasks", tasks, "--concurrency", "1",
+		"--state-file", filepath.Join(dir, "replay.json"), "--replay", cassette)
+	if err != nil {
+		t.Fatalf("create --replay: %v\n%s", err, replayed)
+	}
+	assertContains(t, replayed, "Completed tasks: 5", "Failed tasks: 0", "Project URL: "+projectURL)
+	assertStateCompleted(t, filepath.Join(dir, "replay.json"), true)
+}
+
+func TestReplayNeedsARecording(t *testing.T) {
+	isolate(t)
+	dir := t.TempDir()
+	for name, replay := range map[string]string{"missing": filepath.Join(dir, "typo"), "empty": dir} {
+		_, err := run(t, nil, "create", "--repo", "me/api", "--tasks", writeTasks(t), "--replay", replay)
+		if err == nil || !strings.Contains(err.Error(), "nothing to replay") {
+			t.Errorf("%s replay directory: err = %v, want nothing to replay", name, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "typo")); !os.IsNotExist(err) {
+		t.Errorf("replaying created the missing directory")
+	}
+}
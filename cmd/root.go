@@ -1,8 +1,15 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
+	"github.com/igorcosta/gh-lazy/pkg/config"
 	"github.com/igorcosta/gh-lazy/pkg/github"
 	"github.com/igorcosta/gh-lazy/pkg/utils"
 	"github.com/igorcosta/gh-lazy/pkg/version"
@@ -15,26 +22,32 @@ var rootCmd = &cobra.Command{
 	Long: `gh lazy is a GitHub CLI extension that helps you create project boards,
 issues, milestones, and link them together efficiently.`,
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
-		if cmd.Name() == "version" {
+		if !requiresGitHub(cmd) {
 			return nil
 		}
-		tokenFile, _ := cmd.Flags().GetString("token-file")
-		token, err := utils.GetToken(tokenFile)
+		profile, _ := cmd.Flags().GetString("profile")
+		cfg, err := config.LoadConfig(profile)
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		token, err := githubToken(cmd, cfg)
 		if err != nil {
 			utils.PrintUserGuide()
 			return fmt.Errorf("authentication error: %w", err)
 		}
 
-		client, err := github.NewClient(token)
+		client, err := newClient(token, clientOptions(cmd, cfg))
 		if err != nil {
 			return fmt.Errorf("failed to create GitHub client: %w", err)
 		}
 
-		username, err := client.GetUsername()
+		username, err := client.GetUsername(cmd.Context())
 		if err != nil {
 			return fmt.Errorf("failed to get GitHub username: %w", err)
 		}
 
+		session.cfg, session.client, session.username = cfg, client, username
 		utils.PrintWelcome(username)
 		return nil
 	},
@@ -45,15 +58,154 @@ issues, milestones, and link them together efficiently.`,
 	},
 }
 
+// githubAnnotation marks a command that talks to GitHub. PersistentPreRunE
+// only authenticates for those, so local commands work offline.
+const githubAnnotation = "github"
+
+// session is what PersistentPreRunE set up for a command that talks to
+// GitHub: the config, an authenticated client, and who it's logged in as.
+var session struct {
+	cfg      *config.Config
+	client   github.API
+	username string
+}
+
+func requiresGitHub(cmd *cobra.Command) bool {
+	return cmd.Annotations[githubAnnotation] == "true"
+}
+
+// stringFlag returns the value of a string flag, or fallback if it is empty.
+func stringFlag(cmd *cobra.Command, name, fallback string) string {
+	if value, _ := cmd.Flags().GetString(name); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// ExitError carries a specific process exit code back to main.
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
+// newClient builds the GitHub client commands talk to. Tests replace it to
+// run commands against an in-memory fake.
+var newClient = func(token string, opts github.ClientOptions) (github.API, error) {
+	return github.NewClient(token, opts)
+}
+
+// clientOptions returns the GitHub client settings from the config and the
+// --record and --replay flags.
+func clientOptions(cmd *cobra.Command, cfg *config.Config) github.ClientOptions {
+	record, _ := cmd.Flags().GetString("record")
+	replay, _ := cmd.Flags().GetString("replay")
+	opts := github.ClientOptions{Host: cfg.Host(), Timeout: cfg.GitHub.Timeout, Record: record, Replay: replay}
+	if app := cfg.GitHub.App; app.Enabled() && replay == "" {
+		opts.TokenSource = github.NewAppTokenSource(cfg.Host(), app.ID, app.InstallationID, app.PrivateKeyFile)
+	}
+	return opts
+}
+
+// replayToken stands in for the token when replaying, which needs none.
+const replayToken = "replay"
+
+// githubToken returns the token to talk to GitHub with, looked up in the
+// order utils.ResolveToken describes. It is empty for a GitHub App, whose
+// tokens come from clientOptions' token source.
+func githubToken(cmd *cobra.Command, cfg *config.Config) (string, error) {
+	if replay, _ := cmd.Flags().GetString("replay"); replay != "" {
+		return replayToken, nil
+	}
+	if cfg.GitHub.App.Enabled() {
+		if cfg.GitHub.App.InstallationID == 0 || cfg.GitHub.App.PrivateKeyFile == "" {
+			return "", fmt.Errorf("github.app needs installation_id and private_key_file as well as id")
+		}
+		return "", nil
+	}
+
+	// A token file named by the profile counts as given on the command line,
+	// so it wins over GH_TOKEN.
+	tokenFile := stringFlag(cmd, "token-file", cfg.ProfileTokenFile())
+	token, _, err := utils.ResolveToken(utils.TokenOptions{
+		Host:             cfg.Host(),
+		FlagFile:         tokenFile,
+		ConfigFile:       cfg.TokenFile,
+		CredentialHelper: cfg.CredentialHelper,
+	})
+	return token, err
+}
+
+// appOwner returns the owner of repoName when authenticating as a GitHub App,
+// whose bot account can't own projects, and "" otherwise.
+func appOwner(cfg *config.Config, repoName string) string {
+	if !cfg.GitHub.App.Enabled() {
+		return ""
+	}
+	owner, _, _ := strings.Cut(repoName, "/")
+	return owner
+}
+
+// commandContext returns the context a command talks to GitHub in. It is
+// cancelled by Ctrl+C or SIGTERM, and times out after --timeout, the config
+// file's timeout, or defaultTimeout, in that order. A second Ctrl+C exits
+// immediately.
+func commandContext(cmd *cobra.Command, cfg *config.Config, defaultTimeout time.Duration) (context.Context, context.CancelFunc) {
+	timeout := defaultTimeout
+	if cfg.Timeout > 0 {
+		timeout = cfg.Timeout
+	}
+	if flagTimeout, _ := cmd.Flags().GetDuration("timeout"); flagTimeout > 0 {
+		timeout = flagTimeout
+	}
+
+	// Once the first signal arrives, stop restores the default handling so a
+	// second one kills the process.
+	sigCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-sigCtx.Done()
+		stop()
+	}()
+	ctx, cancel := context.WithTimeout(sigCtx, timeout)
+	return ctx, func() {
+		cancel()
+		stop()
+	}
+}
+
+// interruption describes why ctx ended early, or returns nil if it hasn't.
+func interruption(ctx context.Context) error {
+	switch ctx.Err() {
+	case nil:
+		return nil
+	case context.DeadlineExceeded:
+		return fmt.Errorf("timed out")
+	default:
+		return fmt.Errorf("interrupted")
+	}
+}
+
 func Execute() error {
 	return rootCmd.Execute()
 }
 
 func init() {
 	rootCmd.PersistentFlags().StringP("repo", "r", "", "The repository name (e.g., 'username/repo')")
-	rootCmd.PersistentFlags().StringP("tasks", "t", "", "Path to the tasks JSON file")
+	rootCmd.PersistentFlags().StringP("tasks", "t", "", "Path to the tasks file (JSON, YAML, TOML or Markdown)")
+	rootCmd.PersistentFlags().String("profile", "", "Profile from config.yml to use (default: $LAZY_PROFILE)")
 	rootCmd.PersistentFlags().StringP("token-file", "f", "", "Path to the file containing the GitHub token")
 	rootCmd.PersistentFlags().BoolP("version", "v", false, "Print the version number of gh-lazy")
+	rootCmd.PersistentFlags().String("record", "", "Save every GitHub request and response to this directory, with the token redacted")
+	rootCmd.PersistentFlags().String("replay", "", "Answer GitHub requests from responses saved with --record instead of GitHub")
+	rootCmd.MarkFlagsMutuallyExclusive("record", "replay")
+	rootCmd.PersistentFlags().Duration("timeout", 0, "How long a command may run, e.g. 45m (default: the config file's timeout, or the command's own default)")
 
 	rootCmd.CompletionOptions.DisableDefaultCmd = true
 
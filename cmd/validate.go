@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/igorcosta/gh-lazy/pkg/schema"
+	"github.com/spf13/cobra"
+)
+
+var validateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Validate a tasks file without touching GitHub",
+	Long: `Validate a tasks file against the gh-lazy tasks schema.
+
+Reports missing titles, duplicate issue titles, unparseable or past due dates,
+over-length bodies and unknown keys, each with its path in the file
+(e.g. milestones[2].issues[5].title).
+
+Use --schema to print the JSON Schema for tasks files.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		printSchema, _ := cmd.Flags().GetBool("schema")
+		if printSchema {
+			output, err := json.MarshalIndent(schema.TasksFile(), "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to encode schema: %w", err)
+			}
+			fmt.Println(string(output))
+			return nil
+		}
+
+		tasksFile, _ := cmd.Flags().GetString("tasks")
+		if tasksFile == "" {
+			return fmt.Errorf("tasks file path is required. Use -t or --tasks flag to specify the path")
+		}
+		format, _ := cmd.Flags().GetString("format")
+		strict, _ := cmd.Flags().GetBool("strict")
+
+		problems, err := schema.ValidateFile(tasksFile, format)
+		if err != nil {
+			return fmt.Errorf("failed to validate tasks file: %w", err)
+		}
+
+		if !printProblems(problems) {
+			color.Green("✅ %s is valid", tasksFile)
+			return nil
+		}
+		if schema.HasErrors(problems) || strict {
+			return fmt.Errorf("tasks file %s is invalid", tasksFile)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(validateCmd)
+	validateCmd.Flags().StringP("tasks", "t", "", "Path to the tasks file (JSON, YAML, TOML or Markdown)")
+	validateCmd.Flags().String("format", "", "Tasks file format: json, yaml, toml or markdown (default: detected from the file extension)")
+	validateCmd.Flags().Bool("strict", false, "Treat warnings as errors")
+	validateCmd.Flags().Bool("schema", false, "Print the JSON Schema for tasks files and exit")
+}
+
+// printProblems prints validation problems and reports whether there were any.
+func printProblems(problems []schema.Problem) bool {
+	for _, p := range problems {
+		if p.Warning {
+			color.Yellow("⚠️ %s", p)
+		} else {
+			color.Red("❌ %s", p)
+		}
+	}
+	return len(problems) > 0
+}
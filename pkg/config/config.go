@@ -1,36 +1,172 @@
 package config
 
 import (
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
 	"github.com/spf13/viper"
 )
 
+// DefaultHost is the GitHub host used unless GH_HOST or github.api_url says otherwise.
+const DefaultHost = "github.com"
+
 type Config struct {
-	Repo      string
-	TasksFile string
-	TokenFile string
+	Repo      string `mapstructure:"repo"`
+	TasksFile string `mapstructure:"tasks_file"`
+	TokenFile string `mapstructure:"token_file"`
+	// CredentialHelper is a shell command that prints a GitHub token. It is
+	// the last place a token is looked for.
+	CredentialHelper string `mapstructure:"credential_helper"`
+	// Timeout limits how long a command may run. Zero means each command's
+	// own default.
+	Timeout time.Duration `mapstructure:"timeout"`
+	GitHub  GitHubConfig  `mapstructure:"github"`
+	// Owner and Project are the defaults for --owner and --project.
+	Owner    string             `mapstructure:"owner"`
+	Project  string             `mapstructure:"project"`
+	Profiles map[string]Profile `mapstructure:"profiles"`
+
+	// profile is the name of the active profile, or "" if there is none.
+	profile string
+	// host is the active profile's host.
+	host string
+}
+
+// Profile is a named set of settings for one account, organization or GitHub
+// host. Whatever it sets replaces the top-level setting while it is active.
+type Profile struct {
+	Host             string    `mapstructure:"host"`
+	TokenFile        string    `mapstructure:"token_file"`
+	CredentialHelper string    `mapstructure:"credential_helper"`
+	App              AppConfig `mapstructure:"app"`
+	Owner            string    `mapstructure:"owner"`
+	Repo             string    `mapstructure:"repo"`
+	Project          string    `mapstructure:"project"`
 }
 
-func LoadConfig() (*Config, error) {
-	viper.SetConfigName("config")
-	viper.SetConfigType("yaml")
-	viper.AddConfigPath(".")
-	viper.AutomaticEnv()
+type GitHubConfig struct {
+	// APIURL is the REST API root, such as https://ghes.example.com/api/v3
+	// for GitHub Enterprise Server.
+	APIURL string `mapstructure:"api_url"`
+	// Timeout limits each API request. Zero means no limit.
+	Timeout time.Duration `mapstructure:"timeout"`
+	App     AppConfig     `mapstructure:"app"`
+}
 
-	viper.SetDefault("repo", "")
-	viper.SetDefault("tasks_file", "")
-	viper.SetDefault("token_file", ".token")
+// AppConfig authenticates as a GitHub App installation instead of with a
+// personal token. It is used when ID is set.
+type AppConfig struct {
+	ID             int64  `mapstructure:"id"`
+	InstallationID int64  `mapstructure:"installation_id"`
+	PrivateKeyFile string `mapstructure:"private_key_file"`
+}
 
-	if err := viper.ReadInConfig(); err != nil {
-		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
-			return nil, err
-		}
+// Enabled reports whether app authentication is configured.
+func (a AppConfig) Enabled() bool {
+	return a.ID != 0
+}
+
+// LoadConfig merges the user and repository config files with LAZY_*
+// environment overrides and activates the named profile, or the one in
+// LAZY_PROFILE if profile is empty.
+func LoadConfig(profile string) (*Config, error) {
+	v := viper.New()
+	if err := Read(v); err != nil {
+		return nil, err
 	}
 
 	var config Config
-	err := viper.Unmarshal(&config)
+	err := v.Unmarshal(&config)
 	if err != nil {
 		return nil, err
 	}
 
+	if profile == "" {
+		profile = os.Getenv("LAZY_PROFILE")
+	}
+	if profile != "" {
+		if err := config.useProfile(profile); err != nil {
+			return nil, err
+		}
+	}
 	return &config, nil
 }
+
+func (c *Config) useProfile(name string) error {
+	p, ok := c.Profiles[name]
+	if !ok {
+		names := make([]string, 0, len(c.Profiles))
+		for n := range c.Profiles {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		if len(names) == 0 {
+			return fmt.Errorf("profile %q not found: config.yml has no profiles", name)
+		}
+		return fmt.Errorf("profile %q not found; config.yml has %s", name, strings.Join(names, ", "))
+	}
+
+	c.profile = name
+	c.host = p.Host
+	override(&c.TokenFile, p.TokenFile)
+	override(&c.CredentialHelper, p.CredentialHelper)
+	override(&c.Owner, p.Owner)
+	override(&c.Repo, p.Repo)
+	override(&c.Project, p.Project)
+	// A profile that picks how to authenticate replaces the top-level app,
+	// so a personal profile doesn't keep authenticating as the app.
+	if p.App.Enabled() || p.TokenFile != "" || p.CredentialHelper != "" {
+		c.GitHub.App = p.App
+	}
+	return nil
+}
+
+func override(setting *string, value string) {
+	if value != "" {
+		*setting = value
+	}
+}
+
+// Profile returns the name of the active profile, or "" if there is none.
+func (c *Config) Profile() string {
+	return c.profile
+}
+
+// ProfileTokenFile returns the token file the active profile names, if any.
+func (c *Config) ProfileTokenFile() string {
+	if c.profile == "" {
+		return ""
+	}
+	return c.Profiles[c.profile].TokenFile
+}
+
+// Host returns the GitHub host to talk to: the active profile's host, then
+// GH_HOST, then the host of github.api_url, then github.com.
+func (c *Config) Host() string {
+	if c.host != "" {
+		return c.host
+	}
+	if host := os.Getenv("GH_HOST"); host != "" {
+		return host
+	}
+	if c.GitHub.APIURL == "" {
+		return DefaultHost
+	}
+
+	u, err := url.Parse(c.GitHub.APIURL)
+	if err != nil || u.Hostname() == "" {
+		return DefaultHost
+	}
+	// github.com and GHE.com serve the API from an api. subdomain; GitHub
+	// Enterprise Server serves it from /api/v3 on the host itself, which may
+	// well start with api. too.
+	host := u.Hostname()
+	if rest := strings.TrimPrefix(host, "api."); rest == DefaultHost || strings.HasSuffix(rest, ".ghe.com") {
+		return rest
+	}
+	return host
+}
@@ -0,0 +1,169 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// useLayers points the user and repository layers at files in a temporary
+// directory and returns their paths.
+func useLayers(t *testing.T) (user, repo string) {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(dir, "xdg"))
+	repo = filepath.Join(dir, "config.yml")
+	SetFile(repo)
+	t.Cleanup(func() { SetFile("") })
+	return filepath.Join(dir, "xdg", "gh-lazy", "config.yml"), repo
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// settingEnv sets every setting through its LAZY_* variable.
+var settingEnv = map[string]string{
+	"LAZY_REPO":                        "acme/api",
+	"LAZY_TASKS_FILE":                  "plan.yaml",
+	"LAZY_TOKEN_FILE":                  ".token-ci",
+	"LAZY_CREDENTIAL_HELPER":           "op read op://ci/github",
+	"LAZY_TIMEOUT":                     "45m",
+	"LAZY_GITHUB_API_URL":              "https://github.example.com/api/v3",
+	"LAZY_GITHUB_TIMEOUT":              "30s",
+	"LAZY_GITHUB_APP_ID":               "12",
+	"LAZY_GITHUB_APP_INSTALLATION_ID":  "34",
+	"LAZY_GITHUB_APP_PRIVATE_KEY_FILE": "app.pem",
+	"LAZY_OWNER":                       "acme",
+	"LAZY_PROJECT":                     "https://github.com/orgs/acme/projects/7",
+}
+
+func TestLoadConfigReadsEveryEnvVariable(t *testing.T) {
+	useLayers(t)
+	t.Setenv("LAZY_PROFILE", "")
+
+	for name, value := range settingEnv {
+		t.Setenv(name, value)
+	}
+
+	cfg, err := LoadConfig("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Config{
+		Repo:             "acme/api",
+		TasksFile:        "plan.yaml",
+		TokenFile:        ".token-ci",
+		CredentialHelper: "op read op://ci/github",
+		Timeout:          45 * time.Minute,
+		GitHub: GitHubConfig{
+			APIURL:  "https://github.example.com/api/v3",
+			Timeout: 30 * time.Second,
+			App:     AppConfig{ID: 12, InstallationID: 34, PrivateKeyFile: "app.pem"},
+		},
+		Owner:   "acme",
+		Project: "https://github.com/orgs/acme/projects/7",
+	}
+	if !reflect.DeepEqual(*cfg, want) {
+		t.Errorf("LoadConfig() = %+v\nwant %+v", *cfg, want)
+	}
+}
+
+func TestSettingEnvCoversEverySetting(t *testing.T) {
+	useLayers(t)
+	v := viper.New()
+	if err := Read(v); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range v.AllKeys() {
+		if _, ok := settingEnv[EnvName(key)]; !ok {
+			t.Errorf("settingEnv has no %s for %s", EnvName(key), key)
+		}
+	}
+}
+
+func TestLayersOverrideInOrder(t *testing.T) {
+	user, repo := useLayers(t)
+	t.Setenv("LAZY_PROFILE", "")
+	writeFile(t, user, "owner: from-user\nrepo: user/repo\nproject: \"3\"\n")
+	writeFile(t, repo, "# repo settings\nowner: from-repo\nrepo: repo/repo\n")
+	t.Setenv("LAZY_OWNER", "from-env")
+
+	cfg, err := LoadConfig("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Owner != "from-env" || cfg.Repo != "repo/repo" || cfg.Project != "3" {
+		t.Errorf("got owner %q, repo %q, project %q", cfg.Owner, cfg.Repo, cfg.Project)
+	}
+
+	origins := map[string]string{}
+	settings, err := Settings()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, setting := range settings {
+		origins[setting.Key] = setting.Origin
+	}
+	for key, want := range map[string]string{
+		"owner":      "env:LAZY_OWNER",
+		"repo":       "repo:" + repo,
+		"project":    "user:" + user,
+		"token_file": "default",
+	} {
+		if origins[key] != want {
+			t.Errorf("origin of %s = %q, want %q", key, origins[key], want)
+		}
+	}
+}
+
+func TestSetAndUnsetKeepComments(t *testing.T) {
+	_, repo := useLayers(t)
+	writeFile(t, repo, "# gh-lazy settings\nrepo: acme/api # the default repository\ngithub:\n  # per request\n  timeout: 30s\n")
+
+	if err := Set(repo, "github.app.id", 12); err != nil {
+		t.Fatal(err)
+	}
+	if err := Set(repo, "repo", "acme/web"); err != nil {
+		t.Fatal(err)
+	}
+	if removed, err := Unset(repo, "github.app.id"); err != nil || !removed {
+		t.Fatalf("Unset() = %v, %v", removed, err)
+	}
+
+	data, err := os.ReadFile(repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "# gh-lazy settings\nrepo: acme/web # the default repository\ngithub:\n  # per request\n  timeout: 30s\n"
+	if string(data) != want {
+		t.Errorf("config file is\n%s\nwant\n%s", data, want)
+	}
+}
+
+func TestHostFromAPIURL(t *testing.T) {
+	t.Setenv("GH_HOST", "")
+	for apiURL, want := range map[string]string{
+		"":                                      "github.com",
+		"https://api.github.com":                "github.com",
+		"https://api.acme.ghe.com":              "acme.ghe.com",
+		"https://github.example.com/api/v3":     "github.example.com",
+		"https://api.git.corp.example/api/v3":   "api.git.corp.example",
+		"https://api.github.example.com/api/v3": "api.github.example.com",
+	} {
+		cfg := Config{GitHub: GitHubConfig{APIURL: apiURL}}
+		if got := cfg.Host(); got != want {
+			t.Errorf("Host() for %q = %q, want %q", apiURL, got, want)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Set writes value under the dotted key in the config file at path, creating
+// the file and any parent mappings it needs. Comments and the rest of the
+// file are left as they are.
+func Set(path, key string, value interface{}) error {
+	doc, err := readNode(path)
+	if err != nil {
+		return err
+	}
+
+	var node yaml.Node
+	if err := node.Encode(value); err != nil {
+		return fmt.Errorf("failed to encode %s: %w", key, err)
+	}
+
+	m := doc.Content[0]
+	parts := strings.Split(key, ".")
+	for i, part := range parts {
+		if m.Kind != yaml.MappingNode {
+			return fmt.Errorf("%s in %s is not a mapping", strings.Join(parts[:i], "."), path)
+		}
+		v := lookupNode(m, part)
+		if i == len(parts)-1 {
+			if v == nil {
+				m.Content = append(m.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: part}, &node)
+			} else {
+				node.HeadComment, node.LineComment, node.FootComment = v.HeadComment, v.LineComment, v.FootComment
+				*v = node
+			}
+			break
+		}
+		if v == nil {
+			v = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			m.Content = append(m.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: part}, v)
+		}
+		m = v
+	}
+	return writeNode(path, doc)
+}
+
+// Unset removes the dotted key from the config file at path, along with any
+// mappings it leaves empty. It reports whether the key was there.
+func Unset(path, key string) (bool, error) {
+	doc, err := readNode(path)
+	if err != nil {
+		return false, err
+	}
+	if !unsetNode(doc.Content[0], strings.Split(key, ".")) {
+		return false, nil
+	}
+	return true, writeNode(path, doc)
+}
+
+func unsetNode(m *yaml.Node, parts []string) bool {
+	if m.Kind != yaml.MappingNode {
+		return false
+	}
+	for i := 0; i < len(m.Content); i += 2 {
+		if !strings.EqualFold(m.Content[i].Value, parts[0]) {
+			continue
+		}
+		if len(parts) > 1 {
+			child := m.Content[i+1]
+			if !unsetNode(child, parts[1:]) {
+				return false
+			}
+			if len(child.Content) > 0 {
+				return true
+			}
+		}
+		m.Content = append(m.Content[:i], m.Content[i+2:]...)
+		return true
+	}
+	return false
+}
+
+// lookupNode returns the value for key in mapping m, matching keys
+// case-insensitively as viper does.
+func lookupNode(m *yaml.Node, key string) *yaml.Node {
+	for i := 0; i < len(m.Content); i += 2 {
+		if strings.EqualFold(m.Content[i].Value, key) {
+			return m.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// readNode parses the config file at path, or returns an empty document if
+// it doesn't exist.
+func readNode(path string) (*yaml.Node, error) {
+	doc := &yaml.Node{Kind: yaml.DocumentNode}
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if err == nil {
+		if err := yaml.Unmarshal(data, doc); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+	}
+	if len(doc.Content) == 0 {
+		doc.Kind = yaml.DocumentNode
+		doc.Content = []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}
+	}
+	if doc.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("%s is not a mapping", path)
+	}
+	return doc, nil
+}
+
+func writeNode(path string, doc *yaml.Node) error {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return fmt.Errorf("failed to encode %s: %w", path, err)
+	}
+	if err := enc.Close(); err != nil {
+		return fmt.Errorf("failed to encode %s: %w", path, err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,292 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+)
+
+// fileName is the name of the user and repository config files.
+const fileName = "config.yml"
+
+// envPrefix prefixes environment overrides: github.timeout is LAZY_GITHUB_TIMEOUT.
+const envPrefix = "LAZY"
+
+// Layer is one of the config files that are merged, such as the user's own.
+type Layer struct {
+	// Name is "user" or "repo".
+	Name string
+	Path string
+}
+
+// explicitFile replaces the repository layer when set with SetFile.
+var explicitFile string
+
+// SetFile makes path the repository layer, as the --config flag does.
+func SetFile(path string) {
+	explicitFile = path
+}
+
+// Layers returns the config files in the order they are merged, each
+// overriding the one before: the user's file in $XDG_CONFIG_HOME/gh-lazy,
+// then the repository's. Either may not exist yet.
+func Layers() []Layer {
+	var layers []Layer
+	if path := userFile(); path != "" {
+		layers = append(layers, Layer{Name: "user", Path: path})
+	}
+	return append(layers, Layer{Name: "repo", Path: repoFile()})
+}
+
+// FindLayer returns the layer with the given name.
+func FindLayer(name string) (Layer, error) {
+	for _, layer := range Layers() {
+		if layer.Name == name {
+			return layer, nil
+		}
+	}
+	return Layer{}, fmt.Errorf("no %s config file", name)
+}
+
+// userFile returns $XDG_CONFIG_HOME/gh-lazy/config.yml, falling back to
+// ~/.config like gh does.
+func userFile() string {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "gh-lazy", fileName)
+}
+
+// repoFile returns the nearest config.yml between the current directory and
+// the root of the git repository it is in, or ./config.yml if there is none.
+func repoFile() string {
+	if explicitFile != "" {
+		return explicitFile
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fileName
+	}
+
+	if root := gitRoot(cwd); root != "" {
+		for dir := cwd; ; dir = filepath.Dir(dir) {
+			if path := filepath.Join(dir, fileName); fileExists(path) {
+				return path
+			}
+			if dir == root {
+				break
+			}
+		}
+	}
+	return filepath.Join(cwd, fileName)
+}
+
+func gitRoot(dir string) string {
+	for {
+		if fileExists(filepath.Join(dir, ".git")) {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// defaults are the settings used when no layer sets them.
+var defaults = map[string]interface{}{
+	"repo":              "",
+	"tasks_file":        "",
+	"token_file":        ".token",
+	"credential_helper": "",
+	"timeout":           time.Duration(0),
+	"github.api_url":    "https://api.github.com",
+	"github.timeout":    time.Duration(0),
+}
+
+// Read merges the defaults, every layer and the LAZY_* environment
+// variables into v.
+func Read(v *viper.Viper) error {
+	v.SetConfigType("yaml")
+	for key, value := range defaults {
+		v.SetDefault(key, value)
+	}
+	v.SetEnvPrefix(envPrefix)
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	v.AutomaticEnv()
+	// AutomaticEnv only reaches Unmarshal for keys viper already knows, so
+	// every setting is bound to its variable up front.
+	if err := bindEnv(v, reflect.TypeOf(Config{}), ""); err != nil {
+		return err
+	}
+
+	for _, layer := range Layers() {
+		data, err := os.ReadFile(layer.Path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", layer.Path, err)
+		}
+		if err := v.MergeConfig(bytes.NewReader(data)); err != nil {
+			return fmt.Errorf("failed to parse %s: %w", layer.Path, err)
+		}
+	}
+	return nil
+}
+
+// bindEnv binds the variable of every setting in t, a struct with mapstructure
+// tags. Maps such as profiles have no fixed keys to bind, so they can only be
+// set in a config file.
+func bindEnv(v *viper.Viper, t reflect.Type, prefix string) error {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := field.Tag.Get("mapstructure")
+		if !field.IsExported() || name == "" {
+			continue
+		}
+		key := prefix + name
+		switch {
+		case field.Type.Kind() == reflect.Map:
+			continue
+		case field.Type.Kind() == reflect.Struct:
+			if err := bindEnv(v, field.Type, key+"."); err != nil {
+				return err
+			}
+		default:
+			if err := v.BindEnv(key); err != nil {
+				return fmt.Errorf("failed to bind %s: %w", EnvName(key), err)
+			}
+		}
+	}
+	return nil
+}
+
+// EnvName returns the environment variable that overrides key.
+func EnvName(key string) string {
+	return envPrefix + "_" + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+// Setting is one effective config value and where it came from.
+type Setting struct {
+	Key   string
+	Value interface{}
+	// Origin is "default", "env:LAZY_...", or "<layer>:<path>".
+	Origin string
+}
+
+// Layer returns the name of the config file the setting comes from, or ""
+// if it comes from a default or the environment.
+func (s Setting) Layer() string {
+	name, _, ok := strings.Cut(s.Origin, ":")
+	if !ok || name == "env" {
+		return ""
+	}
+	return name
+}
+
+// Settings returns every effective setting, sorted by key.
+func Settings() ([]Setting, error) {
+	v := viper.New()
+	if err := Read(v); err != nil {
+		return nil, err
+	}
+
+	layerKeys := make([]map[string]bool, 0)
+	layers := Layers()
+	for _, layer := range layers {
+		keys, err := fileKeys(layer.Path)
+		if err != nil {
+			return nil, err
+		}
+		layerKeys = append(layerKeys, keys)
+	}
+
+	keys := v.AllKeys()
+	sort.Strings(keys)
+	settings := make([]Setting, 0, len(keys))
+	for _, key := range keys {
+		value := v.Get(key)
+		if value == nil {
+			// Bound to a variable that isn't set, and set nowhere else.
+			continue
+		}
+		setting := Setting{Key: key, Value: value, Origin: "default"}
+		for i, layer := range layers {
+			if layerKeys[i][key] {
+				setting.Origin = layer.Name + ":" + layer.Path
+			}
+		}
+		if _, ok := os.LookupEnv(EnvName(key)); ok {
+			setting.Origin = "env:" + EnvName(key)
+		}
+		settings = append(settings, setting)
+	}
+	return settings, nil
+}
+
+// Lookup returns the effective setting for key.
+func Lookup(key string) (Setting, bool, error) {
+	settings, err := Settings()
+	if err != nil {
+		return Setting{}, false, err
+	}
+	key = strings.ToLower(key)
+	for _, setting := range settings {
+		if setting.Key == key {
+			return setting, true, nil
+		}
+	}
+	return Setting{}, false, nil
+}
+
+// fileKeys returns the dotted keys of every value set in a config file, the
+// way viper names them. A missing file sets nothing.
+func fileKeys(path string) (map[string]bool, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return map[string]bool{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var values map[string]interface{}
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	keys := map[string]bool{}
+	flattenKeys("", values, keys)
+	return keys, nil
+}
+
+func flattenKeys(prefix string, values map[string]interface{}, keys map[string]bool) {
+	for key, value := range values {
+		key = strings.ToLower(prefix + key)
+		if nested, ok := value.(map[string]interface{}); ok && len(nested) > 0 {
+			flattenKeys(key+".", nested, keys)
+			continue
+		}
+		keys[key] = true
+	}
+}
@@ -0,0 +1,44 @@
+package github
+
+import (
+	"context"
+
+	"github.com/igorcosta/gh-lazy/pkg/models"
+)
+
+// API is every GitHub operation the commands use. *Client implements it
+// against the real API; the fake package implements it in memory.
+type API interface {
+	Host() string
+	IssueURL(owner, repo string, number int) string
+	OnRateLimit(handler RateLimitHandler)
+	GetUsername(ctx context.Context) (string, error)
+	TokenScopes(ctx context.Context) (scopes []string, known bool, err error)
+	GetProjectOwner(ctx context.Context, projectURL string) (string, error)
+
+	CreateProject(ctx context.Context, owner, title string) (string, error)
+	GetProject(ctx context.Context, owner, projectNumber string) (*models.Project, error)
+	ListUserProjects(ctx context.Context, owner string) ([]models.Project, error)
+	DeleteProject(ctx context.Context, owner, projectNumber string) error
+	LinkProjectToRepo(ctx context.Context, owner, projectNumber, repoFullName string) error
+	ListProjectRepos(ctx context.Context, owner, projectNumber string) ([]string, error)
+	AddIssueToProject(ctx context.Context, projectURL, issueURL string) (string, error)
+	ListProjectIssues(ctx context.Context, owner, projectNumber string) ([]models.IssueItem, error)
+
+	CreateIssue(ctx context.Context, owner, repo string, issue models.Issue) (int, error)
+	ListIssues(ctx context.Context, owner, repo string) ([]models.RepoIssue, error)
+	GetIssueTitle(ctx context.Context, repo string, issueNumber int) (string, error)
+	UpdateIssue(ctx context.Context, owner, repo string, issueNumber int, fields map[string]interface{}) error
+	UpdateIssueMilestone(ctx context.Context, owner, repo string, issueNumber, milestoneNumber int) error
+	CloseIssue(ctx context.Context, repo string, issueNumber int) error
+	DeleteIssue(ctx context.Context, repo string, issueNumber int) error
+
+	CreateMilestone(ctx context.Context, owner, repo string, milestone models.Milestone) (int, error)
+	UpdateMilestone(ctx context.Context, owner, repo string, milestoneNumber int, fields map[string]interface{}) error
+	ListMilestones(ctx context.Context, owner, repo string) ([]models.Milestone, error)
+
+	ListLabels(ctx context.Context, owner, repo string) ([]models.Label, error)
+	CreateLabel(ctx context.Context, owner, repo string, label models.Label) error
+}
+
+var _ API = (*Client)(nil)
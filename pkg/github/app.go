@@ -0,0 +1,213 @@
+package github
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cli/go-gh/v2/pkg/api"
+)
+
+const (
+	// appJWTLifetime is how long an app JWT is valid; GitHub allows at most 10 minutes.
+	appJWTLifetime = 9 * time.Minute
+	// appClockSkew backdates JWTs in case our clock runs ahead of GitHub's.
+	appClockSkew = time.Minute
+	// tokenRefreshMargin is how long before it expires an installation token
+	// is replaced.
+	tokenRefreshMargin = 5 * time.Minute
+)
+
+// TokenSource supplies the token for each request, for credentials that
+// expire and have to be refreshed.
+type TokenSource interface {
+	Token(ctx context.Context) (string, error)
+}
+
+// AppTokenSource authenticates as a GitHub App installation. It signs a JWT
+// with the app's private key, exchanges it for an installation token, and
+// exchanges a new one shortly before that token expires.
+type AppTokenSource struct {
+	host           string
+	appID          int64
+	installationID int64
+	privateKeyFile string
+	http           *http.Client
+
+	mu      sync.Mutex
+	key     *rsa.PrivateKey
+	token   string
+	expires time.Time
+	login   string
+}
+
+// NewAppTokenSource returns a token source for an app installation on host.
+// The private key is read from privateKeyFile on first use.
+func NewAppTokenSource(host string, appID, installationID int64, privateKeyFile string) *AppTokenSource {
+	return &AppTokenSource{
+		host:           host,
+		appID:          appID,
+		installationID: installationID,
+		privateKeyFile: privateKeyFile,
+		http:           &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Token returns the current installation token, exchanging a new one if it
+// expires within tokenRefreshMargin.
+func (s *AppTokenSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != "" && time.Until(s.expires) > tokenRefreshMargin {
+		return s.token, nil
+	}
+
+	var response struct {
+		Token     string    `json:"token"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+	path := fmt.Sprintf("app/installations/%d/access_tokens", s.installationID)
+	if err := s.call(ctx, http.MethodPost, path, &response); err != nil {
+		return "", apiError("create installation token", err)
+	}
+	s.token = response.Token
+	s.expires = response.ExpiresAt
+	return s.token, nil
+}
+
+// Login returns the app's bot login, such as lazy-bot[bot]. Installation
+// tokens can't look up the authenticated user, so the Client asks here.
+func (s *AppTokenSource) Login(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.login != "" {
+		return s.login, nil
+	}
+	var response struct {
+		Slug string `json:"slug"`
+	}
+	if err := s.call(ctx, http.MethodGet, "app", &response); err != nil {
+		return "", apiError("get app", err)
+	}
+	s.login = response.Slug + "[bot]"
+	return s.login, nil
+}
+
+// call makes a request authenticated as the app itself. The caller holds s.mu.
+func (s *AppTokenSource) call(ctx context.Context, method, path string, response interface{}) error {
+	jwt, err := s.jwt(time.Now())
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, restRoot(s.host)+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+jwt)
+
+	resp, err := s.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return api.HandleHTTPError(resp)
+	}
+	return json.NewDecoder(resp.Body).Decode(response)
+}
+
+// jwt returns a JWT for the app, signed with its private key. The caller
+// holds s.mu.
+func (s *AppTokenSource) jwt(now time.Time) (string, error) {
+	if s.key == nil {
+		key, err := readPrivateKey(s.privateKeyFile)
+		if err != nil {
+			return "", err
+		}
+		s.key = key
+	}
+
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"RS256","typ":"JWT"}`))
+	claims, err := json.Marshal(map[string]interface{}{
+		"iat": now.Add(-appClockSkew).Unix(),
+		"exp": now.Add(appJWTLifetime).Unix(),
+		"iss": fmt.Sprint(s.appID),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal JWT claims: %w", err)
+	}
+	unsigned := header + "." + base64.RawURLEncoding.EncodeToString(claims)
+
+	digest := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, s.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign JWT: %w", err)
+	}
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// readPrivateKey reads a PEM RSA key, in the PKCS #1 form GitHub hands out
+// or in PKCS #8.
+func readPrivateKey(path string) (*rsa.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read app private key: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("app private key %s is not PEM encoded", path)
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse app private key: %w", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("app private key %s is not an RSA key", path)
+	}
+	return key, nil
+}
+
+// restRoot returns the REST API root URL for host.
+func restRoot(host string) string {
+	if host == "github.com" || strings.HasSuffix(host, ".ghe.com") {
+		return "https://api." + host + "/"
+	}
+	return "https://" + host + "/api/v3/"
+}
+
+// tokenTransport sets each request's Authorization header from a TokenSource.
+type tokenTransport struct {
+	base   http.RoundTripper
+	source TokenSource
+}
+
+func (t *tokenTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := t.source.Token(req.Context())
+	if err != nil {
+		return nil, err
+	}
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "token "+token)
+	return t.base.RoundTrip(req)
+}
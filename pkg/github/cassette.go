@@ -0,0 +1,245 @@
+package github
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// redacted replaces the token, and any Authorization header, in cassettes.
+const redacted = "REDACTED"
+
+// errNotRecorded is returned on replay for a request the cassette can't
+// answer. Retrying it would not help.
+var errNotRecorded = errors.New("no recorded response")
+
+// Interaction is one recorded request and the response GitHub gave it. Each
+// is stored as a numbered JSON file in the cassette directory.
+type Interaction struct {
+	Request struct {
+		Method string      `json:"method"`
+		URL    string      `json:"url"`
+		Header http.Header `json:"header"`
+		Body   string      `json:"body,omitempty"`
+	} `json:"request"`
+	Response struct {
+		StatusCode int         `json:"status_code"`
+		Header     http.Header `json:"header"`
+		Body       string      `json:"body"`
+	} `json:"response"`
+}
+
+// key identifies requests that are answered the same way on replay.
+func (i *Interaction) key() string {
+	return i.Request.Method + " " + i.Request.URL + "\n" + i.Request.Body
+}
+
+// cassette is a directory of interactions. Every client in the process that
+// records to the same directory shares one cassette, and so does every client
+// replaying from it, so that interactions are numbered and consumed in one
+// sequence.
+type cassette struct {
+	mu  sync.Mutex
+	dir string
+	// next is the number of the next recorded interaction.
+	next int
+	// pending holds the interactions not yet replayed, by request.
+	pending map[string][]*Interaction
+}
+
+var (
+	cassettesMu sync.Mutex
+	cassettes   = map[string]*cassette{}
+)
+
+// openCassette returns the cassette for dir, reading what it holds already.
+// Recording creates dir if needed; replaying needs it to hold a recording.
+func openCassette(dir string, replay bool) (*cassette, error) {
+	cassettesMu.Lock()
+	defer cassettesMu.Unlock()
+
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve cassette directory: %w", err)
+	}
+	cacheKey := "record:" + abs
+	if replay {
+		cacheKey = "replay:" + abs
+	}
+	if c := cassettes[cacheKey]; c != nil {
+		return c, nil
+	}
+
+	if replay {
+		if info, err := os.Stat(abs); err != nil || !info.IsDir() {
+			return nil, fmt.Errorf("nothing to replay: %s is not a directory of recorded responses", dir)
+		}
+	} else if err := os.MkdirAll(abs, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create cassette directory: %w", err)
+	}
+	files, err := filepath.Glob(filepath.Join(abs, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cassette directory: %w", err)
+	}
+	if replay && len(files) == 0 {
+		return nil, fmt.Errorf("nothing to replay: %s holds no recorded responses", dir)
+	}
+	sort.Strings(files)
+
+	c := &cassette{dir: abs, next: len(files) + 1, pending: map[string][]*Interaction{}}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read cassette: %w", err)
+		}
+		var interaction Interaction
+		if err := json.Unmarshal(data, &interaction); err != nil {
+			return nil, fmt.Errorf("failed to parse cassette %s: %w", filepath.Base(file), err)
+		}
+		c.pending[interaction.key()] = append(c.pending[interaction.key()], &interaction)
+	}
+	cassettes[cacheKey] = c
+	return c, nil
+}
+
+func (c *cassette) save(interaction *Interaction) error {
+	data, err := json.MarshalIndent(interaction, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal interaction: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	name := fmt.Sprintf("%04d-%s.json", c.next, strings.ToLower(interaction.Request.Method))
+	c.next++
+	if err := os.WriteFile(filepath.Join(c.dir, name), data, 0o644); err != nil {
+		return fmt.Errorf("failed to write cassette: %w", err)
+	}
+	return nil
+}
+
+// take returns the next unreplayed interaction for key, or nil.
+func (c *cassette) take(key string) *Interaction {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	queue := c.pending[key]
+	if len(queue) == 0 {
+		return nil
+	}
+	c.pending[key] = queue[1:]
+	return queue[0]
+}
+
+// recordTransport saves every request sent through it, and the response, to
+// a cassette. The token is redacted wherever it appears.
+type recordTransport struct {
+	base     http.RoundTripper
+	cassette *cassette
+	token    string
+}
+
+func (t *recordTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	interaction := &Interaction{}
+	interaction.Request.Method = req.Method
+	interaction.Request.URL = req.URL.String()
+	interaction.Request.Header = t.redactHeader(req.Header)
+	if req.Body != nil && req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		data, err := io.ReadAll(body)
+		body.Close()
+		if err != nil {
+			return nil, err
+		}
+		interaction.Request.Body = t.redact(string(data))
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(data))
+
+	interaction.Response.StatusCode = resp.StatusCode
+	interaction.Response.Header = t.redactHeader(resp.Header)
+	interaction.Response.Body = t.redact(string(data))
+	if err := t.cassette.save(interaction); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (t *recordTransport) redact(s string) string {
+	if t.token == "" {
+		return s
+	}
+	return strings.ReplaceAll(s, t.token, redacted)
+}
+
+func (t *recordTransport) redactHeader(header http.Header) http.Header {
+	clean := http.Header{}
+	for name, values := range header {
+		for _, value := range values {
+			if strings.EqualFold(name, "Authorization") {
+				value = redacted
+			}
+			clean.Add(name, t.redact(value))
+		}
+	}
+	return clean
+}
+
+// replayTransport answers requests from a cassette instead of GitHub.
+// Identical requests get their recorded responses in the order they were
+// recorded; a request that wasn't recorded, or was already answered as many
+// times as it was recorded, fails.
+type replayTransport struct {
+	cassette *cassette
+}
+
+func (t *replayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body := ""
+	if req.Body != nil {
+		data, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		body = string(data)
+	}
+
+	interaction := t.cassette.take(req.Method + " " + req.URL.String() + "\n" + body)
+	if interaction == nil {
+		return nil, fmt.Errorf("%w for %s %s in %s", errNotRecorded, req.Method, req.URL, t.cassette.dir)
+	}
+	header := interaction.Response.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", interaction.Response.StatusCode, http.StatusText(interaction.Response.StatusCode)),
+		StatusCode:    interaction.Response.StatusCode,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(interaction.Response.Body)),
+		ContentLength: int64(len(interaction.Response.Body)),
+		Request:       req,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+	}, nil
+}
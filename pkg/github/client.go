@@ -2,56 +2,258 @@ package github
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
-	"os/exec"
-	"strings"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sync"
+	"time"
 
 	"github.com/cli/go-gh/v2/pkg/api"
 )
 
 type Client struct {
-	client *api.RESTClient
+	client  *api.RESTClient
+	gql     *api.GraphQLClient
+	limiter *rateLimiter
+	host    string
+	tokens  TokenSource
+
+	mu         sync.Mutex
+	projectIDs map[string]string
+	username   string
+}
+
+// ClientOptions configures which GitHub host a Client talks to and how.
+type ClientOptions struct {
+	// Host is github.com, or the host of a GitHub Enterprise Server.
+	Host string
+	// Timeout limits each attempt at a request; waits for a rate limit to
+	// reset and the retries after them don't count. Zero means no limit.
+	Timeout time.Duration
+	// Record is a directory to save every request and response to, with the
+	// token redacted.
+	Record string
+	// Replay is a directory of recorded responses to answer requests from
+	// instead of GitHub.
+	Replay string
+	// TokenSource, if set, supplies the token for every request in place of
+	// the static token passed to NewClient.
+	TokenSource TokenSource
 }
 
-func NewClient(token string) (*Client, error) {
-	client, err := api.NewRESTClient(api.ClientOptions{
+// loginSource is implemented by token sources that know who they
+// authenticate as without asking the API, such as GitHub Apps.
+type loginSource interface {
+	Login(ctx context.Context) (string, error)
+}
+
+func NewClient(token string, opts ClientOptions) (*Client, error) {
+	if opts.Host == "" {
+		opts.Host = "github.com"
+	}
+
+	if opts.Record != "" && opts.Replay != "" {
+		return nil, fmt.Errorf("cannot record and replay at the same time")
+	}
+
+	if opts.TokenSource != nil {
+		// Fetch the first token now so bad credentials fail here rather
+		// than on the first call.
+		initial, err := opts.TokenSource.Token(context.Background())
+		if err != nil {
+			return nil, err
+		}
+		token = initial
+	}
+
+	var base http.RoundTripper = http.DefaultTransport
+	switch {
+	case opts.Record != "":
+		cassette, err := openCassette(opts.Record, false)
+		if err != nil {
+			return nil, err
+		}
+		base = &recordTransport{base: base, cassette: cassette, token: token}
+	case opts.Replay != "":
+		cassette, err := openCassette(opts.Replay, true)
+		if err != nil {
+			return nil, err
+		}
+		base = &replayTransport{cassette: cassette}
+	}
+
+	if opts.TokenSource != nil {
+		base = &tokenTransport{base: base, source: opts.TokenSource}
+	}
+
+	limiter := &rateLimiter{}
+	apiOpts := api.ClientOptions{
 		AuthToken: token,
-	})
+		Host:      opts.Host,
+		Transport: &rateLimitTransport{base: base, limiter: limiter, timeout: opts.Timeout},
+	}
+	client, err := api.NewRESTClient(apiOpts)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create GitHub client: %w", err)
 	}
-	return &Client{client: client}, nil
+	gql, err := api.NewGraphQLClient(apiOpts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GitHub GraphQL client: %w", err)
+	}
+	return &Client{client: client, gql: gql, limiter: limiter, host: opts.Host, tokens: opts.TokenSource, projectIDs: map[string]string{}}, nil
+}
+
+// Host returns the GitHub host the client talks to.
+func (c *Client) Host() string {
+	return c.host
 }
 
+// IssueURL returns the web URL of an issue on the client's host.
+func (c *Client) IssueURL(owner, repo string, number int) string {
+	return fmt.Sprintf("https://%s/%s/%s/issues/%d", c.host, owner, repo, number)
+}
+
+// OnRateLimit sets a handler that is told whenever a call waits for a rate
+// limit to reset.
+func (c *Client) OnRateLimit(handler RateLimitHandler) {
+	c.limiter.setHandler(handler)
+}
+
+var nextLinkPattern = regexp.MustCompile(`<([^>]+)>;\s*rel="next"`)
+
+// Get fetches path into response. When response points to a slice, every page
+// linked from the Link header is fetched and appended to it.
 func (c *Client) Get(ctx context.Context, path string, response interface{}) error {
-	return c.client.Get(path, response)
+	target := reflect.ValueOf(response)
+	if target.Kind() != reflect.Ptr || target.Elem().Kind() != reflect.Slice {
+		return c.client.DoWithContext(ctx, http.MethodGet, path, nil, response)
+	}
+
+	all := reflect.MakeSlice(target.Elem().Type(), 0, 0)
+	for path != "" {
+		resp, err := c.client.RequestWithContext(ctx, http.MethodGet, path, nil)
+		if err != nil {
+			return err
+		}
+
+		page := reflect.New(target.Elem().Type())
+		err = json.NewDecoder(resp.Body).Decode(page.Interface())
+		resp.Body.Close()
+		if err != nil {
+			return fmt.Errorf("failed to decode response: %w", err)
+		}
+
+		all = reflect.AppendSlice(all, page.Elem())
+		path = nextPage(resp.Header.Get("Link"))
+	}
+
+	target.Elem().Set(all)
+	return nil
+}
+
+// nextPage returns the rel="next" URL from a Link header, or "" on the last page.
+func nextPage(link string) string {
+	matches := nextLinkPattern.FindStringSubmatch(link)
+	if len(matches) != 2 {
+		return ""
+	}
+	return matches[1]
 }
 
 func (c *Client) Post(ctx context.Context, path string, body io.Reader, response interface{}) error {
-	return c.client.Post(path, body, response)
+	return c.client.DoWithContext(ctx, http.MethodPost, path, body, response)
 }
 
 func (c *Client) Patch(ctx context.Context, path string, body io.Reader, response interface{}) error {
-	return c.client.Patch(path, body, response)
+	return c.client.DoWithContext(ctx, http.MethodPatch, path, body, response)
 }
 
-func (c *Client) GetProjectOwner(ctx context.Context, projectNumber string) (string, error) {
-	cmd := exec.CommandContext(ctx, "gh", "project", "view", projectNumber, "--json", "owner", "--jq", ".owner.login")
-	output, err := cmd.Output()
-	if err != nil {
-		return "", fmt.Errorf("failed to get project owner: %w", err)
+var projectOwnerPattern = regexp.MustCompile(`/(?:orgs|users)/([^/]+)/projects/\d+/?$`)
+
+// ProjectOwnerFromURL returns the user or organization in a project URL such
+// as https://github.com/orgs/acme/projects/7, or "" if input isn't one.
+func ProjectOwnerFromURL(input string) string {
+	matches := projectOwnerPattern.FindStringSubmatch(input)
+	if len(matches) != 2 {
+		return ""
+	}
+	return matches[1]
+}
+
+// GetProjectOwner returns the user or organization that owns the project at
+// projectURL. For anything that isn't a project URL, such as a bare project
+// number, it falls back to the authenticated user.
+func (c *Client) GetProjectOwner(ctx context.Context, projectURL string) (string, error) {
+	if owner := ProjectOwnerFromURL(projectURL); owner != "" {
+		return owner, nil
 	}
-	return strings.TrimSpace(string(output)), nil
+	return c.resolveOwner(ctx, "")
 }
 
-func (c *Client) GetUsername() (string, error) {
-	var response struct {
-		Login string `json:"login"`
+// resolveOwner returns owner, or the authenticated user's login if owner is
+// empty. A GitHub App's bot account can't own projects, so an app has to name
+// the owner.
+func (c *Client) resolveOwner(ctx context.Context, owner string) (string, error) {
+	if owner != "" {
+		return owner, nil
 	}
-	err := c.Get(context.Background(), "user", &response)
+	if _, ok := c.tokens.(loginSource); ok {
+		return "", fmt.Errorf("a GitHub App can't own projects; pass --owner or set owner in config.yml")
+	}
+	username, err := c.GetUsername(ctx)
 	if err != nil {
-		return "", fmt.Errorf("failed to get username: %w", err)
+		return "", fmt.Errorf("failed to get GitHub username: %w", err)
+	}
+	return username, nil
+}
+
+// TokenScopes returns the OAuth scopes the token was granted. known is false
+// when GitHub doesn't say, as for fine-grained and GitHub App tokens, whose
+// permissions are set per repository instead.
+func (c *Client) TokenScopes(ctx context.Context) (scopes []string, known bool, err error) {
+	resp, err := c.client.RequestWithContext(ctx, http.MethodGet, "rate_limit", nil)
+	if err != nil {
+		return nil, false, apiError("check token scopes", err)
+	}
+	resp.Body.Close()
+
+	if _, known := resp.Header[http.CanonicalHeaderKey("X-OAuth-Scopes")]; !known {
+		return nil, false, nil
 	}
-	return response.Login, nil
+	return splitScopes(resp.Header.Get("X-OAuth-Scopes")), true, nil
+}
+
+// GetUsername returns the login of the authenticated user. It is looked up
+// once per client.
+func (c *Client) GetUsername(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	username := c.username
+	c.mu.Unlock()
+	if username != "" {
+		return username, nil
+	}
+
+	if source, ok := c.tokens.(loginSource); ok {
+		login, err := source.Login(ctx)
+		if err != nil {
+			return "", err
+		}
+		username = login
+	} else {
+		var response struct {
+			Login string `json:"login"`
+		}
+		if err := c.Get(ctx, "user", &response); err != nil {
+			return "", apiError("get username", err)
+		}
+		username = response.Login
+	}
+
+	c.mu.Lock()
+	c.username = username
+	c.mu.Unlock()
+	return username, nil
 }
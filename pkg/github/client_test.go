@@ -0,0 +1,82 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/igorcosta/gh-lazy/pkg/models"
+)
+
+func TestGetFollowsNextLinks(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Query().Get("page") {
+		case "":
+			w.Header().Set("Link", fmt.Sprintf(`<%s/issues?page=2>; rel="next", <%s/issues?page=2>; rel="last"`, server.URL, server.URL))
+			fmt.Fprint(w, `[{"number": 1, "title": "One"}, {"number": 2, "title": "Two"}]`)
+		case "2":
+			w.Header().Set("Link", fmt.Sprintf(`<%s/issues>; rel="first", <%s/issues>; rel="prev"`, server.URL, server.URL))
+			fmt.Fprint(w, `[{"number": 3, "title": "Three"}]`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClient("token", ClientOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var issues []models.RepoIssue
+	if err := client.Get(context.Background(), server.URL+"/issues", &issues); err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) != 3 {
+		t.Fatalf("got %d issues, want 3 from two pages: %+v", len(issues), issues)
+	}
+	for i, issue := range issues {
+		if issue.Number != i+1 {
+			t.Errorf("issues[%d] is #%d, want #%d", i, issue.Number, i+1)
+		}
+	}
+}
+
+func TestGetDecodesObjectsWithoutPaging(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Link", `<http://example.invalid/user?page=2>; rel="next"`)
+		fmt.Fprint(w, `{"login": "me"}`)
+	}))
+	defer server.Close()
+
+	client, err := NewClient("token", ClientOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var user struct {
+		Login string `json:"login"`
+	}
+	if err := client.Get(context.Background(), server.URL+"/user", &user); err != nil {
+		t.Fatal(err)
+	}
+	if user.Login != "me" {
+		t.Errorf("login = %q, want me", user.Login)
+	}
+}
+
+func TestNextPage(t *testing.T) {
+	tests := map[string]string{
+		`<https://api.github.com/repos/a/b/issues?page=2>; rel="next", <https://api.github.com/repos/a/b/issues?page=5>; rel="last"`:  "https://api.github.com/repos/a/b/issues?page=2",
+		`<https://api.github.com/repos/a/b/issues?page=4>; rel="prev", <https://api.github.com/repos/a/b/issues?page=1>; rel="first"`: "",
+		"": "",
+	}
+	for link, want := range tests {
+		if got := nextPage(link); got != want {
+			t.Errorf("nextPage(%q) = %q, want %q", link, got, want)
+		}
+	}
+}
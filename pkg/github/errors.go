@@ -0,0 +1,191 @@
+package github
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/cli/go-gh/v2/pkg/api"
+)
+
+// ErrorKind classifies why GitHub rejected a call.
+type ErrorKind int
+
+const (
+	KindOther ErrorKind = iota
+	// KindNotFound means the resource doesn't exist, or the token can't see it.
+	KindNotFound
+	// KindForbidden means the token lacks a scope or permission.
+	KindForbidden
+	// KindRateLimited means a rate limit was still exhausted after retrying.
+	KindRateLimited
+	// KindValidation means GitHub refused the input, such as a duplicate title.
+	KindValidation
+)
+
+// Sentinels for errors.Is; every *Error matches the one for its Kind.
+var (
+	ErrNotFound    = errors.New("not found")
+	ErrForbidden   = errors.New("forbidden")
+	ErrRateLimited = errors.New("rate limited")
+	ErrValidation  = errors.New("validation failed")
+)
+
+// Error is returned by Client calls that GitHub rejected. It wraps the
+// underlying *api.HTTPError or *api.GraphQLError.
+type Error struct {
+	Kind ErrorKind
+	// Op is what was being done, such as "create project".
+	Op string
+	// StatusCode is the HTTP status of a REST call; GraphQL calls have none.
+	StatusCode int
+	// Type is the GraphQL error type, such as NOT_FOUND or INSUFFICIENT_SCOPES.
+	Type string
+	// Path is the dotted path of the GraphQL field that failed, if any.
+	Path string
+	// MissingScopes lists OAuth scopes the call needs and the token lacks,
+	// when GitHub says which.
+	MissingScopes []string
+	Message       string
+	Err           error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("failed to %s: %s", e.Op, e.Message)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func (e *Error) Is(target error) bool {
+	switch e.Kind {
+	case KindNotFound:
+		return target == ErrNotFound
+	case KindForbidden:
+		return target == ErrForbidden
+	case KindRateLimited:
+		return target == ErrRateLimited
+	case KindValidation:
+		return target == ErrValidation
+	}
+	return false
+}
+
+// AsError returns the *Error in err's chain, or nil if there is none.
+func AsError(err error) *Error {
+	var e *Error
+	if errors.As(err, &e) {
+		return e
+	}
+	return nil
+}
+
+var graphQLKinds = map[string]ErrorKind{
+	"NOT_FOUND":           KindNotFound,
+	"FORBIDDEN":           KindForbidden,
+	"INSUFFICIENT_SCOPES": KindForbidden,
+	"RATE_LIMITED":        KindRateLimited,
+	"UNPROCESSABLE":       KindValidation,
+}
+
+// requiredScopesPattern finds the scope list in GraphQL scope errors such as
+// "... requires one of the following scopes: ['project'], but ...".
+var (
+	requiredScopesPattern = regexp.MustCompile(`following scopes: \[([^\]]*)\]`)
+	quotedScopePattern    = regexp.MustCompile(`'([^']+)'`)
+)
+
+// apiError classifies an error from the REST or GraphQL client. Errors that
+// didn't come from GitHub, such as network failures, are wrapped as KindOther.
+func apiError(op string, err error) error {
+	e := &Error{Op: op, Message: err.Error(), Err: err}
+
+	var httpErr *api.HTTPError
+	var gqlErr *api.GraphQLError
+	switch {
+	case errors.As(err, &httpErr):
+		e.StatusCode = httpErr.StatusCode
+		e.Message = fmt.Sprintf("HTTP %d: %s", httpErr.StatusCode, httpErr.Message)
+		e.Kind = httpErrorKind(httpErr)
+		if e.Kind == KindForbidden || e.Kind == KindNotFound {
+			e.MissingScopes = missingScopes(httpErr.Headers)
+		}
+	case errors.As(err, &gqlErr) && len(gqlErr.Errors) > 0:
+		item := gqlErr.Errors[0]
+		e.Type = item.Type
+		e.Message = item.Message
+		e.Kind = graphQLKinds[item.Type]
+		path := make([]string, len(item.Path))
+		for i, p := range item.Path {
+			path[i] = fmt.Sprint(p)
+		}
+		e.Path = strings.Join(path, ".")
+		if item.Type == "INSUFFICIENT_SCOPES" {
+			if required := requiredScopesPattern.FindStringSubmatch(item.Message); required != nil {
+				for _, match := range quotedScopePattern.FindAllStringSubmatch(required[1], -1) {
+					e.MissingScopes = append(e.MissingScopes, match[1])
+				}
+			}
+		}
+	}
+	return e
+}
+
+// notFound returns a KindNotFound error for a lookup that came back empty.
+func notFound(op, message string) error {
+	return &Error{Kind: KindNotFound, Op: op, Message: message}
+}
+
+func httpErrorKind(err *api.HTTPError) ErrorKind {
+	switch err.StatusCode {
+	case http.StatusNotFound, http.StatusGone:
+		return KindNotFound
+	case http.StatusUnauthorized:
+		return KindForbidden
+	case http.StatusForbidden:
+		if err.Headers.Get("X-RateLimit-Remaining") == "0" || secondaryRateLimitPattern.MatchString(err.Message) {
+			return KindRateLimited
+		}
+		return KindForbidden
+	case http.StatusTooManyRequests:
+		return KindRateLimited
+	case http.StatusUnprocessableEntity:
+		return KindValidation
+	}
+	return KindOther
+}
+
+// missingScopes compares the scopes an endpoint accepts with those the token
+// has. GitHub only sends these headers for OAuth and classic tokens.
+func missingScopes(headers http.Header) []string {
+	accepted := splitScopes(headers.Get("X-Accepted-OAuth-Scopes"))
+	if len(accepted) == 0 {
+		return nil
+	}
+	granted := map[string]bool{}
+	for _, scope := range splitScopes(headers.Get("X-OAuth-Scopes")) {
+		granted[scope] = true
+	}
+
+	var missing []string
+	for _, scope := range accepted {
+		if granted[scope] {
+			return nil
+		}
+		missing = append(missing, scope)
+	}
+	return missing
+}
+
+func splitScopes(header string) []string {
+	var scopes []string
+	for _, scope := range strings.Split(header, ",") {
+		if scope = strings.TrimSpace(scope); scope != "" {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes
+}
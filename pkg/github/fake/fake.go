@@ -0,0 +1,821 @@
+// Package fake is an in-memory GitHub that implements github.API, so that
+// commands can be run without a network. Faults can be injected into any
+// operation to exercise error handling.
+package fake
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/igorcosta/gh-lazy/pkg/github"
+	"github.com/igorcosta/gh-lazy/pkg/models"
+)
+
+// Fault makes calls to an operation fail. Op is the github.API method name,
+// such as "CreateIssue". The first After calls succeed; after that the next
+// Times calls fail with Err, or every call does if Times is 0.
+//
+// A fault with a RateLimitWait and no Err doesn't fail the call; it reports a
+// rate limit wait of that length to the OnRateLimit handler first.
+type Fault struct {
+	Op            string
+	After         int
+	Times         int
+	Err           error
+	RateLimitWait time.Duration
+
+	hits int
+}
+
+// Error returns a typed GitHub error, as the real client would, for use as a
+// Fault's Err.
+func Error(kind github.ErrorKind, op, message string) *github.Error {
+	return &github.Error{Kind: kind, Op: op, Message: message}
+}
+
+type repository struct {
+	issues     []models.RepoIssue
+	milestones []models.Milestone
+	labels     []models.Label
+	// nextNumber is shared by issues and pull requests, as on GitHub.
+	nextNumber    int
+	nextMilestone int
+}
+
+type project struct {
+	models.Project
+	owner string
+	items []models.IssueItem
+	repos []string
+}
+
+// GitHub is an in-memory GitHub. Repositories have to be added with AddRepo
+// before they can be used; calls on any other repository fail with
+// github.ErrNotFound. It is safe for concurrent use.
+type GitHub struct {
+	mu          sync.Mutex
+	host        string
+	login       string
+	orgs        map[string]bool
+	repos       map[string]*repository
+	projects    map[string]*project
+	nextProject map[string]int
+	nextItem    int
+	faults      []*Fault
+	calls       map[string]int
+	handler     github.RateLimitHandler
+	scopes      []string
+	scopesKnown bool
+}
+
+var _ github.API = (*GitHub)(nil)
+
+// New returns an empty GitHub on github.com, authenticated as login.
+func New(login string) *GitHub {
+	return &GitHub{
+		host:        "github.com",
+		login:       login,
+		orgs:        map[string]bool{},
+		repos:       map[string]*repository{},
+		projects:    map[string]*project{},
+		nextProject: map[string]int{},
+		calls:       map[string]int{},
+	}
+}
+
+// SetHost changes the host used in issue and project URLs.
+func (g *GitHub) SetHost(host string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.host = host
+}
+
+// SetScopes makes TokenScopes report scopes. Until it is called, the token's
+// scopes are unknown, as for a fine-grained token.
+func (g *GitHub) SetScopes(scopes ...string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.scopes = scopes
+	g.scopesKnown = true
+}
+
+// AddOrg makes login an organization, so its project URLs use /orgs/.
+func (g *GitHub) AddOrg(login string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.orgs[login] = true
+}
+
+// AddRepo creates an empty repository named "owner/repo".
+func (g *GitHub) AddRepo(fullName string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.repos[fullName] == nil {
+		g.repos[fullName] = &repository{nextNumber: 1, nextMilestone: 1}
+	}
+}
+
+// Inject adds a fault. Faults are checked in the order they were added.
+func (g *GitHub) Inject(fault Fault) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.faults = append(g.faults, &fault)
+}
+
+// Calls returns how many times op has been called, including failed calls.
+func (g *GitHub) Calls(op string) int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.calls[op]
+}
+
+// Issues returns the issues in a repository, in the order they were created.
+func (g *GitHub) Issues(fullName string) []models.RepoIssue {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if r := g.repos[fullName]; r != nil {
+		return copyIssues(r.issues)
+	}
+	return nil
+}
+
+// Milestones returns the milestones in a repository.
+func (g *GitHub) Milestones(fullName string) []models.Milestone {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if r := g.repos[fullName]; r != nil {
+		return append([]models.Milestone{}, r.milestones...)
+	}
+	return nil
+}
+
+// Labels returns the labels in a repository.
+func (g *GitHub) Labels(fullName string) []models.Label {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if r := g.repos[fullName]; r != nil {
+		return append([]models.Label{}, r.labels...)
+	}
+	return nil
+}
+
+// LinkedRepos returns the repositories a project is linked to.
+func (g *GitHub) LinkedRepos(owner, projectNumber string) []string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if p := g.projects[owner+"/"+projectNumber]; p != nil {
+		return append([]string{}, p.repos...)
+	}
+	return nil
+}
+
+func (g *GitHub) Host() string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.host
+}
+
+func (g *GitHub) IssueURL(owner, repo string, number int) string {
+	return fmt.Sprintf("https://%s/%s/%s/issues/%d", g.Host(), owner, repo, number)
+}
+
+func (g *GitHub) OnRateLimit(handler github.RateLimitHandler) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.handler = handler
+}
+
+func (g *GitHub) GetUsername(ctx context.Context) (string, error) {
+	if err := g.call(ctx, "GetUsername"); err != nil {
+		return "", err
+	}
+	return g.login, nil
+}
+
+func (g *GitHub) TokenScopes(ctx context.Context) ([]string, bool, error) {
+	if err := g.call(ctx, "TokenScopes"); err != nil {
+		return nil, false, err
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return append([]string(nil), g.scopes...), g.scopesKnown, nil
+}
+
+func (g *GitHub) GetProjectOwner(ctx context.Context, projectURL string) (string, error) {
+	if err := g.call(ctx, "GetProjectOwner"); err != nil {
+		return "", err
+	}
+	if owner := github.ProjectOwnerFromURL(projectURL); owner != "" {
+		return owner, nil
+	}
+	return g.login, nil
+}
+
+func (g *GitHub) CreateProject(ctx context.Context, owner, title string) (string, error) {
+	if err := g.call(ctx, "CreateProject"); err != nil {
+		return "", err
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	owner = g.owner(owner)
+	g.nextProject[owner]++
+	number := g.nextProject[owner]
+	kind := "users"
+	if g.orgs[owner] {
+		kind = "orgs"
+	}
+	p := &project{
+		Project: models.Project{
+			ID:     fmt.Sprintf("PVT_%s_%d", owner, number),
+			Number: number,
+			Title:  title,
+			URL:    fmt.Sprintf("https://%s/%s/%s/projects/%d", g.host, kind, owner, number),
+		},
+		owner: owner,
+	}
+	g.projects[owner+"/"+strconv.Itoa(number)] = p
+	return p.URL, nil
+}
+
+func (g *GitHub) GetProject(ctx context.Context, owner, projectNumber string) (*models.Project, error) {
+	if err := g.call(ctx, "GetProject"); err != nil {
+		return nil, err
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	p, err := g.project("get project "+projectNumber, owner, projectNumber)
+	if err != nil {
+		return nil, err
+	}
+	project := p.Project
+	return &project, nil
+}
+
+func (g *GitHub) ListUserProjects(ctx context.Context, owner string) ([]models.Project, error) {
+	if err := g.call(ctx, "ListUserProjects"); err != nil {
+		return nil, err
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	owner = g.owner(owner)
+	var projects []models.Project
+	for _, p := range g.projects {
+		if p.owner == owner {
+			projects = append(projects, p.Project)
+		}
+	}
+	sort.Slice(projects, func(i, j int) bool { return projects[i].Number < projects[j].Number })
+	return projects, nil
+}
+
+func (g *GitHub) DeleteProject(ctx context.Context, owner, projectNumber string) error {
+	if err := g.call(ctx, "DeleteProject"); err != nil {
+		return err
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	p, err := g.project("delete project", owner, projectNumber)
+	if err != nil {
+		return err
+	}
+	delete(g.projects, p.owner+"/"+projectNumber)
+	return nil
+}
+
+func (g *GitHub) LinkProjectToRepo(ctx context.Context, owner, projectNumber, repoFullName string) error {
+	if err := g.call(ctx, "LinkProjectToRepo"); err != nil {
+		return err
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	parts := strings.Split(repoFullName, "/")
+	if len(parts) != 2 {
+		return fmt.Errorf("invalid repository format: %s", repoFullName)
+	}
+	if owner == "" {
+		owner = parts[0]
+	}
+	p, err := g.project("link project to repository", owner, projectNumber)
+	if err != nil {
+		return err
+	}
+	if _, err := g.repo("look up repository "+repoFullName, repoFullName); err != nil {
+		return err
+	}
+	for _, linked := range p.repos {
+		if linked == repoFullName {
+			return nil
+		}
+	}
+	p.repos = append(p.repos, repoFullName)
+	return nil
+}
+
+func (g *GitHub) ListProjectRepos(ctx context.Context, owner, projectNumber string) ([]string, error) {
+	if err := g.call(ctx, "ListProjectRepos"); err != nil {
+		return nil, err
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	p, err := g.project("list repositories of project "+projectNumber, owner, projectNumber)
+	if err != nil {
+		return nil, err
+	}
+	return append([]string{}, p.repos...), nil
+}
+
+func (g *GitHub) AddIssueToProject(ctx context.Context, projectURL, issueURL string) (string, error) {
+	if err := g.call(ctx, "AddIssueToProject"); err != nil {
+		return "", err
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	parts := strings.Split(projectURL, "/")
+	owner := github.ProjectOwnerFromURL(projectURL)
+	p, err := g.project("add issue to project", owner, parts[len(parts)-1])
+	if err != nil {
+		return "", err
+	}
+
+	repoName, number := g.parseIssueURL(issueURL)
+	issue := g.issue(repoName, number)
+	if issue == nil {
+		return "", notFound("look up issue "+issueURL, "no issue at "+issueURL)
+	}
+
+	// Adding an issue that is already on the board returns the existing item.
+	for _, item := range p.items {
+		if item.Repository == repoName && item.Number == number {
+			return item.ID, nil
+		}
+	}
+	g.nextItem++
+	item := models.IssueItem{ID: fmt.Sprintf("PVTI_%d", g.nextItem), Number: number, Title: issue.Title, Repository: repoName}
+	p.items = append(p.items, item)
+	return item.ID, nil
+}
+
+func (g *GitHub) ListProjectIssues(ctx context.Context, owner, projectNumber string) ([]models.IssueItem, error) {
+	if err := g.call(ctx, "ListProjectIssues"); err != nil {
+		return nil, err
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	p, err := g.project("get project "+projectNumber, owner, projectNumber)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]models.IssueItem, len(p.items))
+	for i, item := range p.items {
+		if issue := g.issue(item.Repository, item.Number); issue != nil {
+			item.Title = issue.Title
+		}
+		items[i] = item
+	}
+	return items, nil
+}
+
+func (g *GitHub) CreateIssue(ctx context.Context, owner, repo string, issue models.Issue) (int, error) {
+	if err := g.call(ctx, "CreateIssue"); err != nil {
+		return 0, err
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	r, err := g.repo("create issue", owner+"/"+repo)
+	if err != nil {
+		return 0, err
+	}
+	if issue.Title == "" {
+		return 0, Error(github.KindValidation, "create issue", "title can't be blank")
+	}
+
+	created := models.RepoIssue{
+		Number: r.nextNumber,
+		Title:  issue.Title,
+		Body:   github.WithKeyMarker(issue.Body, issue.Key),
+		State:  "open",
+	}
+	r.nextNumber++
+	for _, name := range issue.Labels {
+		created.Labels = append(created.Labels, r.label(name))
+	}
+	for _, login := range issue.Assignees {
+		created.Assignees = append(created.Assignees, models.User{Login: login})
+	}
+	r.issues = append(r.issues, created)
+	return created.Number, nil
+}
+
+func (g *GitHub) ListIssues(ctx context.Context, owner, repo string) ([]models.RepoIssue, error) {
+	if err := g.call(ctx, "ListIssues"); err != nil {
+		return nil, err
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	r, err := g.repo("get issues", owner+"/"+repo)
+	if err != nil {
+		return nil, err
+	}
+	return copyIssues(r.issues), nil
+}
+
+func (g *GitHub) GetIssueTitle(ctx context.Context, repo string, issueNumber int) (string, error) {
+	if err := g.call(ctx, "GetIssueTitle"); err != nil {
+		return "", err
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	issue := g.issue(repo, issueNumber)
+	if issue == nil {
+		return "", notFound(fmt.Sprintf("look up issue #%d", issueNumber), fmt.Sprintf("%s has no issue #%d", repo, issueNumber))
+	}
+	return issue.Title, nil
+}
+
+func (g *GitHub) UpdateIssue(ctx context.Context, owner, repo string, issueNumber int, fields map[string]interface{}) error {
+	if err := g.call(ctx, "UpdateIssue"); err != nil {
+		return err
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	op := fmt.Sprintf("update issue #%d", issueNumber)
+	r, err := g.repo(op, owner+"/"+repo)
+	if err != nil {
+		return err
+	}
+	issue := g.issue(owner+"/"+repo, issueNumber)
+	if issue == nil {
+		return notFound(op, fmt.Sprintf("%s/%s has no issue #%d", owner, repo, issueNumber))
+	}
+
+	for field, value := range fields {
+		switch field {
+		case "title":
+			issue.Title = fmt.Sprint(value)
+		case "body":
+			issue.Body = fmt.Sprint(value)
+		case "state":
+			issue.State = fmt.Sprint(value)
+		case "milestone":
+			number, _ := value.(int)
+			milestone := r.milestone(number)
+			if milestone == nil {
+				return Error(github.KindValidation, op, fmt.Sprintf("no milestone #%d", number))
+			}
+			issue.Milestone = milestone
+		case "labels":
+			issue.Labels = nil
+			for _, name := range value.([]string) {
+				issue.Labels = append(issue.Labels, r.label(name))
+			}
+		case "assignees":
+			issue.Assignees = nil
+			for _, login := range value.([]string) {
+				issue.Assignees = append(issue.Assignees, models.User{Login: login})
+			}
+		default:
+			return Error(github.KindValidation, op, "unknown field "+field)
+		}
+	}
+	return nil
+}
+
+func (g *GitHub) UpdateIssueMilestone(ctx context.Context, owner, repo string, issueNumber, milestoneNumber int) error {
+	if err := g.call(ctx, "UpdateIssueMilestone"); err != nil {
+		return err
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	r, err := g.repo("update issue milestone", owner+"/"+repo)
+	if err != nil {
+		return err
+	}
+	issue := g.issue(owner+"/"+repo, issueNumber)
+	if issue == nil {
+		return notFound("update issue milestone", fmt.Sprintf("%s/%s has no issue #%d", owner, repo, issueNumber))
+	}
+	milestone := r.milestone(milestoneNumber)
+	if milestone == nil {
+		return Error(github.KindValidation, "update issue milestone", fmt.Sprintf("no milestone #%d", milestoneNumber))
+	}
+	issue.Milestone = milestone
+	return nil
+}
+
+func (g *GitHub) CloseIssue(ctx context.Context, repo string, issueNumber int) error {
+	if err := g.call(ctx, "CloseIssue"); err != nil {
+		return err
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	issue := g.issue(repo, issueNumber)
+	if issue == nil {
+		return notFound(fmt.Sprintf("close issue #%d", issueNumber), fmt.Sprintf("%s has no issue #%d", repo, issueNumber))
+	}
+	issue.State = "closed"
+	return nil
+}
+
+// DeleteIssue removes an issue, and with it any project items pointing at it.
+func (g *GitHub) DeleteIssue(ctx context.Context, repo string, issueNumber int) error {
+	if err := g.call(ctx, "DeleteIssue"); err != nil {
+		return err
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	op := fmt.Sprintf("delete issue #%d", issueNumber)
+	r, err := g.repo(op, repo)
+	if err != nil {
+		return err
+	}
+	for i, issue := range r.issues {
+		if issue.Number != issueNumber {
+			continue
+		}
+		r.issues = append(r.issues[:i], r.issues[i+1:]...)
+		for _, p := range g.projects {
+			items := p.items[:0]
+			for _, item := range p.items {
+				if item.Repository != repo || item.Number != issueNumber {
+					items = append(items, item)
+				}
+			}
+			p.items = items
+		}
+		return nil
+	}
+	return notFound(op, fmt.Sprintf("%s has no issue #%d", repo, issueNumber))
+}
+
+func (g *GitHub) CreateMilestone(ctx context.Context, owner, repo string, milestone models.Milestone) (int, error) {
+	if err := g.call(ctx, "CreateMilestone"); err != nil {
+		return 0, err
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	r, err := g.repo("create milestone", owner+"/"+repo)
+	if err != nil {
+		return 0, err
+	}
+	for _, existing := range r.milestones {
+		if existing.Title == milestone.Title {
+			return 0, Error(github.KindValidation, "create milestone", "a milestone titled "+milestone.Title+" already exists")
+		}
+	}
+
+	created := milestone
+	created.Number = r.nextMilestone
+	created.Description = github.WithKeyMarker(milestone.Description, milestone.Key)
+	if !milestone.DueOn.IsZero() {
+		created.DueOn = models.Date{Time: milestone.DueOn.UTC().Truncate(time.Second)}
+	}
+	if created.State == "" {
+		created.State = "open"
+	}
+	r.nextMilestone++
+	r.milestones = append(r.milestones, created)
+	return created.Number, nil
+}
+
+func (g *GitHub) UpdateMilestone(ctx context.Context, owner, repo string, milestoneNumber int, fields map[string]interface{}) error {
+	if err := g.call(ctx, "UpdateMilestone"); err != nil {
+		return err
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	op := fmt.Sprintf("update milestone #%d", milestoneNumber)
+	r, err := g.repo(op, owner+"/"+repo)
+	if err != nil {
+		return err
+	}
+	milestone := r.milestone(milestoneNumber)
+	if milestone == nil {
+		return notFound(op, fmt.Sprintf("%s/%s has no milestone #%d", owner, repo, milestoneNumber))
+	}
+
+	for field, value := range fields {
+		switch field {
+		case "title":
+			milestone.Title = fmt.Sprint(value)
+		case "description":
+			milestone.Description = fmt.Sprint(value)
+			milestone.Key = github.ExtractKey(milestone.Description)
+		case "state":
+			milestone.State = fmt.Sprint(value)
+		case "due_on":
+			dueOn, _ := value.(time.Time)
+			milestone.DueOn = models.Date{Time: dueOn}
+		default:
+			return Error(github.KindValidation, op, "unknown field "+field)
+		}
+	}
+	for i := range r.milestones {
+		if r.milestones[i].Number == milestoneNumber {
+			r.milestones[i] = *milestone
+		}
+	}
+	return nil
+}
+
+func (g *GitHub) ListMilestones(ctx context.Context, owner, repo string) ([]models.Milestone, error) {
+	if err := g.call(ctx, "ListMilestones"); err != nil {
+		return nil, err
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	r, err := g.repo("get milestones", owner+"/"+repo)
+	if err != nil {
+		return nil, err
+	}
+	milestones := append([]models.Milestone{}, r.milestones...)
+	for i := range milestones {
+		milestones[i].Key = github.ExtractKey(milestones[i].Description)
+	}
+	return milestones, nil
+}
+
+func (g *GitHub) ListLabels(ctx context.Context, owner, repo string) ([]models.Label, error) {
+	if err := g.call(ctx, "ListLabels"); err != nil {
+		return nil, err
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	r, err := g.repo("get labels", owner+"/"+repo)
+	if err != nil {
+		return nil, err
+	}
+	return append([]models.Label{}, r.labels...), nil
+}
+
+func (g *GitHub) CreateLabel(ctx context.Context, owner, repo string, label models.Label) error {
+	if err := g.call(ctx, "CreateLabel"); err != nil {
+		return err
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	r, err := g.repo("create label "+label.Name, owner+"/"+repo)
+	if err != nil {
+		return err
+	}
+	for _, existing := range r.labels {
+		if strings.EqualFold(existing.Name, label.Name) {
+			return Error(github.KindValidation, "create label "+label.Name, "label "+label.Name+" already exists")
+		}
+	}
+	label.Color = strings.TrimPrefix(label.Color, "#")
+	if label.Color == "" {
+		label.Color = github.DefaultLabelColor
+	}
+	r.labels = append(r.labels, label)
+	return nil
+}
+
+// call records a call to op and returns the error of the first fault that
+// fires for it. It fails like the real client once ctx is done.
+func (g *GitHub) call(ctx context.Context, op string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	g.mu.Lock()
+	g.calls[op]++
+	n := g.calls[op]
+	handler := g.handler
+	var wait time.Duration
+	var err error
+	for _, f := range g.faults {
+		if f.Op != op || n <= f.After || (f.Times > 0 && f.hits >= f.Times) {
+			continue
+		}
+		f.hits++
+		if f.Err == nil {
+			wait = f.RateLimitWait
+			continue
+		}
+		err = f.Err
+		break
+	}
+	g.mu.Unlock()
+
+	if wait > 0 && handler != nil {
+		handler(wait)
+		handler(0)
+	}
+	return err
+}
+
+// owner returns owner, or the authenticated user if owner is empty. The
+// caller holds g.mu.
+func (g *GitHub) owner(owner string) string {
+	if owner == "" {
+		return g.login
+	}
+	return owner
+}
+
+// The caller holds g.mu.
+func (g *GitHub) project(op, owner, projectNumber string) (*project, error) {
+	owner = g.owner(owner)
+	p := g.projects[owner+"/"+projectNumber]
+	if p == nil {
+		return nil, notFound(op, fmt.Sprintf("%s has no project %s", owner, projectNumber))
+	}
+	return p, nil
+}
+
+// The caller holds g.mu.
+func (g *GitHub) repo(op, fullName string) (*repository, error) {
+	r := g.repos[fullName]
+	if r == nil {
+		return nil, notFound(op, "no repository named "+fullName)
+	}
+	return r, nil
+}
+
+// issue returns the stored issue, or nil. The caller holds g.mu.
+func (g *GitHub) issue(fullName string, number int) *models.RepoIssue {
+	r := g.repos[fullName]
+	if r == nil {
+		return nil
+	}
+	for i := range r.issues {
+		if r.issues[i].Number == number {
+			return &r.issues[i]
+		}
+	}
+	return nil
+}
+
+// parseIssueURL splits https://host/owner/repo/issues/N into "owner/repo"
+// and N. The caller holds g.mu.
+func (g *GitHub) parseIssueURL(issueURL string) (string, int) {
+	path := strings.TrimPrefix(issueURL, "https://"+g.host+"/")
+	parts := strings.Split(path, "/")
+	if len(parts) != 4 || parts[2] != "issues" {
+		return "", 0
+	}
+	number, _ := strconv.Atoi(parts[3])
+	return parts[0] + "/" + parts[1], number
+}
+
+// milestone returns a copy of the milestone with the given number, or nil.
+func (r *repository) milestone(number int) *models.Milestone {
+	for _, m := range r.milestones {
+		if m.Number == number {
+			return &m
+		}
+	}
+	return nil
+}
+
+// label returns the label called name, creating it as GitHub does when an
+// issue uses a label the repository doesn't have yet.
+func (r *repository) label(name string) models.Label {
+	for _, existing := range r.labels {
+		if strings.EqualFold(existing.Name, name) {
+			return existing
+		}
+	}
+	label := models.Label{Name: name, Color: github.DefaultLabelColor}
+	r.labels = append(r.labels, label)
+	return label
+}
+
+func notFound(op, message string) error {
+	return Error(github.KindNotFound, op, message)
+}
+
+func copyIssues(issues []models.RepoIssue) []models.RepoIssue {
+	copied := make([]models.RepoIssue, len(issues))
+	for i, issue := range issues {
+		issue.Labels = append([]models.Label(nil), issue.Labels...)
+		issue.Assignees = append([]models.User(nil), issue.Assignees...)
+		copied[i] = issue
+	}
+	return copied
+}
@@ -0,0 +1,177 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/igorcosta/gh-lazy/pkg/models"
+)
+
+// RepoIndex is an in-memory snapshot of a repository's issues, milestones,
+// labels and project items. It is loaded once up front and kept current as
+// items are created, so lookups don't cost an API call each.
+type RepoIndex struct {
+	mu           sync.Mutex
+	issues       []models.RepoIssue
+	milestones   []models.Milestone
+	labels       map[string]bool
+	projectItems map[string]string
+}
+
+// LoadRepoIndex lists the repository's issues, milestones and labels.
+func LoadRepoIndex(ctx context.Context, c API, owner, repo string) (*RepoIndex, error) {
+	issues, err := c.ListIssues(ctx, owner, repo)
+	if err != nil {
+		return nil, err
+	}
+	milestones, err := c.ListMilestones(ctx, owner, repo)
+	if err != nil {
+		return nil, err
+	}
+	labels, err := c.ListLabels(ctx, owner, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	idx := &RepoIndex{
+		issues:       issues,
+		milestones:   milestones,
+		labels:       map[string]bool{},
+		projectItems: map[string]string{},
+	}
+	for _, label := range labels {
+		idx.labels[strings.ToLower(label.Name)] = true
+	}
+	return idx, nil
+}
+
+// LoadProjectItems adds the issues already on a project board to the index.
+func LoadProjectItems(ctx context.Context, c API, idx *RepoIndex, owner, projectNumber string) error {
+	items, err := c.ListProjectIssues(ctx, owner, projectNumber)
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		idx.AddProjectItem(item.Repository, item.Number, item.ID)
+	}
+	return nil
+}
+
+// Issues returns a copy of the indexed issues.
+func (idx *RepoIndex) Issues() []models.RepoIssue {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	return append([]models.RepoIssue{}, idx.issues...)
+}
+
+// Milestones returns a copy of the indexed milestones.
+func (idx *RepoIndex) Milestones() []models.Milestone {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	return append([]models.Milestone{}, idx.milestones...)
+}
+
+// FindIssue matches a tasks file issue by key, falling back to title. A title
+// match only counts if the issue isn't tagged with a different key.
+func (idx *RepoIndex) FindIssue(issue models.Issue) *models.RepoIssue {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	if issue.Key != "" {
+		for i := range idx.issues {
+			if ExtractKey(idx.issues[i].Body) == issue.Key {
+				found := idx.issues[i]
+				return &found
+			}
+		}
+	}
+	for i := range idx.issues {
+		if idx.issues[i].Title == issue.Title && MatchesKey(idx.issues[i].Body, issue.Key) {
+			found := idx.issues[i]
+			return &found
+		}
+	}
+	return nil
+}
+
+// FindMilestone matches a tasks file milestone by key, falling back to title.
+func (idx *RepoIndex) FindMilestone(milestone models.Milestone) *models.Milestone {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	if milestone.Key != "" {
+		for i := range idx.milestones {
+			if idx.milestones[i].Key == milestone.Key {
+				found := idx.milestones[i]
+				return &found
+			}
+		}
+	}
+	for i := range idx.milestones {
+		if idx.milestones[i].Title == milestone.Title && MatchesKey(idx.milestones[i].Description, milestone.Key) {
+			found := idx.milestones[i]
+			return &found
+		}
+	}
+	return nil
+}
+
+// HasLabel reports whether the repository has a label, ignoring case.
+func (idx *RepoIndex) HasLabel(name string) bool {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	return idx.labels[strings.ToLower(name)]
+}
+
+// ProjectItem returns the project item ID of an issue already on the board.
+func (idx *RepoIndex) ProjectItem(repoFullName string, issueNumber int) (string, bool) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	itemID, ok := idx.projectItems[projectItemKey(repoFullName, issueNumber)]
+	return itemID, ok
+}
+
+func (idx *RepoIndex) AddIssue(issue models.RepoIssue) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.issues = append(idx.issues, issue)
+}
+
+func (idx *RepoIndex) AddMilestone(milestone models.Milestone) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.milestones = append(idx.milestones, milestone)
+}
+
+func (idx *RepoIndex) AddLabel(name string) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.labels[strings.ToLower(name)] = true
+}
+
+func (idx *RepoIndex) AddProjectItem(repoFullName string, issueNumber int, itemID string) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.projectItems[projectItemKey(repoFullName, issueNumber)] = itemID
+}
+
+// SetIssueMilestone records that an issue now belongs to a milestone.
+func (idx *RepoIndex) SetIssueMilestone(issueNumber int, milestone models.Milestone) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	for i := range idx.issues {
+		if idx.issues[i].Number == issueNumber {
+			idx.issues[i].Milestone = &milestone
+		}
+	}
+}
+
+// MatchesKey reports whether text carries no key marker or the given key.
+func MatchesKey(text, key string) bool {
+	existingKey := ExtractKey(text)
+	return existingKey == "" || existingKey == key
+}
+
+func projectItemKey(repoFullName string, issueNumber int) string {
+	return strings.ToLower(fmt.Sprintf("%s#%d", repoFullName, issueNumber))
+}
@@ -5,9 +5,9 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"os/exec"
 	"strings"
 
+	graphql "github.com/cli/shurcooL-graphql"
 	"github.com/igorcosta/gh-lazy/pkg/models"
 )
 
@@ -17,29 +17,53 @@ func (c *Client) CreateIssue(ctx context.Context, owner, repo string, issue mode
 		Number int `json:"number"`
 	}
 
-	payload, err := json.Marshal(issue)
+	// The key travels in the body as a hidden marker rather than as a field.
+	issueCopy := issue
+	issueCopy.Body = WithKeyMarker(issue.Body, issue.Key)
+	issueCopy.Key = ""
+
+	payload, err := json.Marshal(issueCopy)
 	if err != nil {
 		return 0, fmt.Errorf("failed to marshal issue: %w", err)
 	}
 
 	if err := c.Post(ctx, url, bytes.NewReader(payload), &response); err != nil {
-		return 0, fmt.Errorf("failed to create issue: %w", err)
+		return 0, apiError("create issue", err)
 	}
 	return response.Number, nil
 }
 
-func (c *Client) GetIssueByTitle(ctx context.Context, owner, repo, title string) (*models.Issue, error) {
-	url := fmt.Sprintf("repos/%s/%s/issues?state=all", owner, repo)
-	var issues []models.Issue
-	if err := c.Get(ctx, url, &issues); err != nil {
-		return nil, fmt.Errorf("failed to get issues: %w", err)
+// ListIssues returns every issue in the repository, open or closed. Pull
+// requests, which the issues API also returns, are left out.
+func (c *Client) ListIssues(ctx context.Context, owner, repo string) ([]models.RepoIssue, error) {
+	url := fmt.Sprintf("repos/%s/%s/issues?state=all&per_page=100", owner, repo)
+	var response []models.RepoIssue
+	if err := c.Get(ctx, url, &response); err != nil {
+		return nil, apiError("get issues", err)
 	}
-	for _, i := range issues {
-		if i.Title == title {
-			return &i, nil
+	issues := make([]models.RepoIssue, 0, len(response))
+	for _, i := range response {
+		if i.PullRequest == nil {
+			issues = append(issues, i)
 		}
 	}
-	return nil, nil
+	return issues, nil
+}
+
+// UpdateIssue patches the given fields of an issue.
+func (c *Client) UpdateIssue(ctx context.Context, owner, repo string, issueNumber int, fields map[string]interface{}) error {
+	url := fmt.Sprintf("repos/%s/%s/issues/%d", owner, repo, issueNumber)
+
+	jsonPayload, err := json.Marshal(fields)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	var response interface{}
+	if err := c.Patch(ctx, url, bytes.NewReader(jsonPayload), &response); err != nil {
+		return apiError(fmt.Sprintf("update issue #%d", issueNumber), err)
+	}
+	return nil
 }
 
 func (c *Client) UpdateIssueMilestone(ctx context.Context, owner, repo string, issueNumber, milestoneNumber int) error {
@@ -55,31 +79,80 @@ func (c *Client) UpdateIssueMilestone(ctx context.Context, owner, repo string, i
 
 	var response interface{}
 	if err := c.Patch(ctx, url, bytes.NewReader(jsonPayload), &response); err != nil {
-		return fmt.Errorf("failed to update issue milestone: %w", err)
+		return apiError("update issue milestone", err)
 	}
 	return nil
 }
 
+type CloseIssueInput struct {
+	IssueID graphql.ID `json:"issueId"`
+}
+
+type DeleteIssueInput struct {
+	IssueID graphql.ID `json:"issueId"`
+}
+
 func (c *Client) CloseIssue(ctx context.Context, repo string, issueNumber int) error {
-	cmd := exec.CommandContext(ctx, "gh", "issue", "close", fmt.Sprintf("%d", issueNumber), "--repo", repo, "--yes")
-	output, err := cmd.CombinedOutput()
+	issue, err := c.lookupIssue(ctx, repo, issueNumber)
 	if err != nil {
-		return fmt.Errorf("failed to close issue #%d: %s - %w", issueNumber, string(output), err)
+		return err
+	}
+
+	var mutation struct {
+		CloseIssue struct {
+			ClientMutationID string
+		} `graphql:"closeIssue(input: $input)"`
+	}
+	if err := c.gql.MutateWithContext(ctx, "CloseIssue", &mutation, map[string]interface{}{
+		"input": CloseIssueInput{IssueID: graphql.ID(issue.ID)},
+	}); err != nil {
+		return apiError(fmt.Sprintf("close issue #%d", issueNumber), err)
 	}
 	return nil
 }
 
 func (c *Client) DeleteIssue(ctx context.Context, repo string, issueNumber int) error {
+	issue, err := c.lookupIssue(ctx, repo, issueNumber)
+	if err != nil {
+		return err
+	}
+
+	var mutation struct {
+		DeleteIssue struct {
+			ClientMutationID string
+		} `graphql:"deleteIssue(input: $input)"`
+	}
+	if err := c.gql.MutateWithContext(ctx, "DeleteIssue", &mutation, map[string]interface{}{
+		"input": DeleteIssueInput{IssueID: graphql.ID(issue.ID)},
+	}); err != nil {
+		return apiError(fmt.Sprintf("delete issue #%d", issueNumber), err)
+	}
+	return nil
+}
+
+type issueNode struct {
+	ID    string
+	Title string
+}
+
+// lookupIssue returns the node ID and title of an issue in "owner/repo".
+func (c *Client) lookupIssue(ctx context.Context, repo string, issueNumber int) (*issueNode, error) {
 	parts := strings.Split(repo, "/")
 	if len(parts) != 2 {
-		return fmt.Errorf("invalid repository format: %s", repo)
+		return nil, fmt.Errorf("invalid repository format: %s", repo)
 	}
-	owner, repoName := parts[0], parts[1]
 
-	cmd := exec.CommandContext(ctx, "gh", "issue", "delete", fmt.Sprintf("%d", issueNumber), "--repo", fmt.Sprintf("%s/%s", owner, repoName), "--yes")
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("failed to delete issue #%d: %s - %w", issueNumber, string(output), err)
+	var query struct {
+		Repository struct {
+			Issue issueNode `graphql:"issue(number: $number)"`
+		} `graphql:"repository(owner: $owner, name: $name)"`
 	}
-	return nil
+	if err := c.gql.QueryWithContext(ctx, "IssueByNumber", &query, map[string]interface{}{
+		"owner":  graphql.String(parts[0]),
+		"name":   graphql.String(parts[1]),
+		"number": graphql.Int(issueNumber),
+	}); err != nil {
+		return nil, apiError(fmt.Sprintf("look up issue #%d", issueNumber), err)
+	}
+	return &query.Repository.Issue, nil
 }
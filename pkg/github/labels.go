@@ -0,0 +1,44 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/igorcosta/gh-lazy/pkg/models"
+)
+
+// DefaultLabelColor is used for labels that are not declared in the tasks file.
+const DefaultLabelColor = "ededed"
+
+func (c *Client) ListLabels(ctx context.Context, owner, repo string) ([]models.Label, error) {
+	url := fmt.Sprintf("repos/%s/%s/labels?per_page=100", owner, repo)
+	var labels []models.Label
+	if err := c.Get(ctx, url, &labels); err != nil {
+		return nil, apiError("get labels", err)
+	}
+	return labels, nil
+}
+
+func (c *Client) CreateLabel(ctx context.Context, owner, repo string, label models.Label) error {
+	url := fmt.Sprintf("repos/%s/%s/labels", owner, repo)
+
+	labelCopy := label
+	labelCopy.Color = strings.TrimPrefix(label.Color, "#")
+	if labelCopy.Color == "" {
+		labelCopy.Color = DefaultLabelColor
+	}
+
+	payload, err := json.Marshal(labelCopy)
+	if err != nil {
+		return fmt.Errorf("failed to marshal label: %w", err)
+	}
+
+	var response interface{}
+	if err := c.Post(ctx, url, bytes.NewReader(payload), &response); err != nil {
+		return apiError("create label "+label.Name, err)
+	}
+	return nil
+}
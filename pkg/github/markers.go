@@ -0,0 +1,36 @@
+package github
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var keyMarkerPattern = regexp.MustCompile(`<!--\s*gh-lazy:key=(\S+?)\s*-->`)
+
+// KeyMarker returns the hidden HTML comment used to tag an issue body or
+// milestone description with its tasks file key.
+func KeyMarker(key string) string {
+	return fmt.Sprintf("<!-- gh-lazy:key=%s -->", key)
+}
+
+// WithKeyMarker appends the key marker to text, replacing any existing one.
+func WithKeyMarker(text, key string) string {
+	text = strings.TrimRight(keyMarkerPattern.ReplaceAllString(text, ""), "\n ")
+	if key == "" {
+		return text
+	}
+	if text == "" {
+		return KeyMarker(key)
+	}
+	return text + "\n\n" + KeyMarker(key)
+}
+
+// ExtractKey returns the key stored in text by WithKeyMarker, if any.
+func ExtractKey(text string) string {
+	matches := keyMarkerPattern.FindStringSubmatch(text)
+	if len(matches) < 2 {
+		return ""
+	}
+	return matches[1]
+}
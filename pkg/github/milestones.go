@@ -19,8 +19,10 @@ func (c *Client) CreateMilestone(ctx context.Context, owner, repo string, milest
 	// Convert the time.Time to the correct string format
 	milestoneCopy := milestone
 	if !milestone.DueOn.IsZero() {
-		milestoneCopy.DueOn = milestone.DueOn.UTC().Truncate(time.Second)
+		milestoneCopy.DueOn = models.Date{Time: milestone.DueOn.UTC().Truncate(time.Second)}
 	}
+	milestoneCopy.Description = WithKeyMarker(milestone.Description, milestone.Key)
+	milestoneCopy.Key = ""
 
 	payload, err := json.Marshal(milestoneCopy)
 	if err != nil {
@@ -28,21 +30,36 @@ func (c *Client) CreateMilestone(ctx context.Context, owner, repo string, milest
 	}
 
 	if err := c.Post(ctx, url, bytes.NewReader(payload), &response); err != nil {
-		return 0, fmt.Errorf("failed to create milestone: %w", err)
+		return 0, apiError("create milestone", err)
 	}
 	return response.Number, nil
 }
 
-func (c *Client) GetMilestoneByTitle(ctx context.Context, owner, repo, title string) (*models.Milestone, error) {
-	url := fmt.Sprintf("repos/%s/%s/milestones", owner, repo)
+// UpdateMilestone patches the given fields of a milestone.
+func (c *Client) UpdateMilestone(ctx context.Context, owner, repo string, milestoneNumber int, fields map[string]interface{}) error {
+	url := fmt.Sprintf("repos/%s/%s/milestones/%d", owner, repo, milestoneNumber)
+
+	payload, err := json.Marshal(fields)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	var response interface{}
+	if err := c.Patch(ctx, url, bytes.NewReader(payload), &response); err != nil {
+		return apiError(fmt.Sprintf("update milestone #%d", milestoneNumber), err)
+	}
+	return nil
+}
+
+// ListMilestones returns every milestone in the repository, open or closed.
+func (c *Client) ListMilestones(ctx context.Context, owner, repo string) ([]models.Milestone, error) {
+	url := fmt.Sprintf("repos/%s/%s/milestones?state=all&per_page=100", owner, repo)
 	var milestones []models.Milestone
 	if err := c.Get(ctx, url, &milestones); err != nil {
-		return nil, fmt.Errorf("failed to get milestones: %w", err)
+		return nil, apiError("get milestones", err)
 	}
-	for _, m := range milestones {
-		if m.Title == title {
-			return &m, nil
-		}
+	for i := range milestones {
+		milestones[i].Key = ExtractKey(milestones[i].Description)
 	}
-	return nil, nil
+	return milestones, nil
 }
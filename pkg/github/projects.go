@@ -2,175 +2,426 @@ package github
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"os/exec"
+	"strconv"
 	"strings"
 
+	graphql "github.com/cli/shurcooL-graphql"
 	"github.com/igorcosta/gh-lazy/pkg/models"
 )
 
-func (c *Client) CreateProject(ctx context.Context, title string) (string, error) {
-	owner, err := c.GetUsername()
+// pageSize is how many projects or project items are requested per page.
+const pageSize = 100
+
+type pageInfo struct {
+	HasNextPage bool
+	EndCursor   string
+}
+
+// URI is the GraphQL URI scalar.
+type URI string
+
+type CreateProjectV2Input struct {
+	OwnerID graphql.ID     `json:"ownerId"`
+	Title   graphql.String `json:"title"`
+}
+
+type AddProjectV2ItemByIdInput struct {
+	ProjectID graphql.ID `json:"projectId"`
+	ContentID graphql.ID `json:"contentId"`
+}
+
+type DeleteProjectV2Input struct {
+	ProjectID graphql.ID `json:"projectId"`
+}
+
+type LinkProjectV2ToRepositoryInput struct {
+	ProjectID    graphql.ID `json:"projectId"`
+	RepositoryID graphql.ID `json:"repositoryId"`
+}
+
+func (c *Client) CreateProject(ctx context.Context, owner, title string) (string, error) {
+	owner, err := c.resolveOwner(ctx, owner)
 	if err != nil {
-		return "", fmt.Errorf("failed to get GitHub username: %w", err)
+		return "", err
 	}
 
-	cmd := exec.CommandContext(ctx, "gh", "project", "create", "--owner", owner, "--title", title, "--format", "json")
-	output, err := cmd.Output()
-	if err != nil {
-		if exitError, ok := err.(*exec.ExitError); ok {
-			return "", fmt.Errorf("failed to create project: %s", string(exitError.Stderr))
-		}
-		return "", fmt.Errorf("failed to create project: %w", err)
+	var query struct {
+		RepositoryOwner struct {
+			ID string
+		} `graphql:"repositoryOwner(login: $owner)"`
 	}
-	var response struct {
-		URL string `json:"url"`
+	if err := c.gql.QueryWithContext(ctx, "ProjectOwner", &query, map[string]interface{}{
+		"owner": graphql.String(owner),
+	}); err != nil {
+		return "", apiError("look up project owner "+owner, err)
+	}
+	if query.RepositoryOwner.ID == "" {
+		return "", notFound("look up project owner "+owner, "no user or organization named "+owner)
+	}
+
+	var mutation struct {
+		CreateProjectV2 struct {
+			ProjectV2 models.Project
+		} `graphql:"createProjectV2(input: $input)"`
 	}
-	if err := json.Unmarshal(output, &response); err != nil {
-		return "", fmt.Errorf("failed to parse project creation output: %w", err)
+	if err := c.gql.MutateWithContext(ctx, "CreateProject", &mutation, map[string]interface{}{
+		"input": CreateProjectV2Input{OwnerID: graphql.ID(query.RepositoryOwner.ID), Title: graphql.String(title)},
+	}); err != nil {
+		return "", apiError("create project", err)
 	}
-	return response.URL, nil
+
+	project := mutation.CreateProjectV2.ProjectV2
+	c.cacheProjectID(owner, strconv.Itoa(project.Number), project.ID)
+	return project.URL, nil
 }
 
-func (c *Client) AddIssueToProject(ctx context.Context, projectURL, issueURL string) error {
-	owner, err := c.GetUsername()
+// AddIssueToProject adds an issue to a project and returns the project item ID.
+func (c *Client) AddIssueToProject(ctx context.Context, projectURL, issueURL string) (string, error) {
+	owner, err := c.GetProjectOwner(ctx, projectURL)
 	if err != nil {
-		return fmt.Errorf("failed to get GitHub username: %w", err)
+		return "", err
 	}
 
 	// Extract project number from URL
 	parts := strings.Split(projectURL, "/")
 	projectNumber := parts[len(parts)-1]
 
-	cmd := exec.CommandContext(ctx, "gh", "project", "item-add", projectNumber,
-		"--owner", owner, "--url", issueURL)
-
-	output, err := cmd.CombinedOutput()
+	projectID, err := c.projectID(ctx, owner, projectNumber)
 	if err != nil {
-		return fmt.Errorf("failed to add issue to project: %s - %w", string(output), err)
+		return "", err
 	}
 
-	return nil
+	var query struct {
+		Resource struct {
+			Issue struct {
+				ID string
+			} `graphql:"... on Issue"`
+		} `graphql:"resource(url: $url)"`
+	}
+	if err := c.gql.QueryWithContext(ctx, "IssueByURL", &query, map[string]interface{}{
+		"url": URI(issueURL),
+	}); err != nil {
+		return "", apiError("look up issue "+issueURL, err)
+	}
+	if query.Resource.Issue.ID == "" {
+		return "", notFound("look up issue "+issueURL, "no issue at "+issueURL)
+	}
+
+	var mutation struct {
+		AddProjectV2ItemById struct {
+			Item struct {
+				ID string
+			}
+		} `graphql:"addProjectV2ItemById(input: $input)"`
+	}
+	if err := c.gql.MutateWithContext(ctx, "AddProjectItem", &mutation, map[string]interface{}{
+		"input": AddProjectV2ItemByIdInput{ProjectID: graphql.ID(projectID), ContentID: graphql.ID(query.Resource.Issue.ID)},
+	}); err != nil {
+		return "", apiError("add issue to project", err)
+	}
+	return mutation.AddProjectV2ItemById.Item.ID, nil
 }
 
-func (c *Client) ListUserProjects(ctx context.Context) ([]models.Project, error) {
-	owner, err := c.GetUsername()
+func (c *Client) ListUserProjects(ctx context.Context, owner string) ([]models.Project, error) {
+	owner, err := c.resolveOwner(ctx, owner)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get GitHub username: %w", err)
+		return nil, err
 	}
 
-	cmd := exec.CommandContext(ctx, "gh", "project", "list", "--owner", owner, "--format", "json")
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return nil, fmt.Errorf("failed to list projects: %s - %w", string(output), err)
+	type projectConnection struct {
+		Nodes    []models.Project
+		PageInfo pageInfo
+	}
+	var query struct {
+		RepositoryOwner struct {
+			Typename string `graphql:"__typename"`
+			User     struct {
+				ProjectsV2 projectConnection `graphql:"projectsV2(first: $first, after: $cursor)"`
+			} `graphql:"... on User"`
+			Organization struct {
+				ProjectsV2 projectConnection `graphql:"projectsV2(first: $first, after: $cursor)"`
+			} `graphql:"... on Organization"`
+		} `graphql:"repositoryOwner(login: $owner)"`
 	}
 
-	var result models.ProjectListResponse
-	if err := json.Unmarshal(output, &result); err != nil {
-		return nil, fmt.Errorf("failed to parse projects: %w", err)
+	var projects []models.Project
+	variables := map[string]interface{}{
+		"owner":  graphql.String(owner),
+		"first":  graphql.Int(pageSize),
+		"cursor": (*graphql.String)(nil),
 	}
+	for {
+		if err := c.gql.QueryWithContext(ctx, "ListProjects", &query, variables); err != nil {
+			return nil, apiError("list projects", err)
+		}
 
-	return result.Projects, nil
-}
-func (c *Client) ListProjectIssues(ctx context.Context, projectNumber string) ([]models.IssueItem, error) {
-	owner, err := c.GetUsername()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get GitHub username: %w", err)
+		page := query.RepositoryOwner.User.ProjectsV2
+		if query.RepositoryOwner.Typename == "Organization" {
+			page = query.RepositoryOwner.Organization.ProjectsV2
+		}
+		projects = append(projects, page.Nodes...)
+		for _, project := range page.Nodes {
+			c.cacheProjectID(owner, strconv.Itoa(project.Number), project.ID)
+		}
+
+		if !page.PageInfo.HasNextPage {
+			return projects, nil
+		}
+		variables["cursor"] = graphql.String(page.PageInfo.EndCursor)
 	}
+}
 
-	cmd := exec.CommandContext(ctx, "gh", "project", "item-list", projectNumber, "--owner", owner, "--format", "json")
-	output, err := cmd.CombinedOutput()
+func (c *Client) ListProjectIssues(ctx context.Context, owner, projectNumber string) ([]models.IssueItem, error) {
+	owner, err := c.resolveOwner(ctx, owner)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list project items: %s - %w", string(output), err)
+		return nil, err
 	}
 
-	var result map[string]interface{}
-	if err := json.Unmarshal(output, &result); err != nil {
-		return nil, fmt.Errorf("failed to parse project items: %w", err)
+	projectID, err := c.projectID(ctx, owner, projectNumber)
+	if err != nil {
+		return nil, err
 	}
 
-	items, ok := result["items"].([]interface{})
-	if !ok {
-		return nil, fmt.Errorf("unexpected JSON structure: 'items' is not an array")
+	var query struct {
+		Node struct {
+			ProjectV2 struct {
+				Items struct {
+					Nodes []struct {
+						ID      string
+						Content struct {
+							Issue struct {
+								Number     int
+								Title      string
+								Repository struct {
+									NameWithOwner string
+								}
+							} `graphql:"... on Issue"`
+						}
+					}
+					PageInfo pageInfo
+				} `graphql:"items(first: $first, after: $cursor)"`
+			} `graphql:"... on ProjectV2"`
+		} `graphql:"node(id: $id)"`
 	}
 
 	var issues []models.IssueItem
-	for _, item := range items {
-		itemMap, ok := item.(map[string]interface{})
-		if !ok {
-			continue
+	variables := map[string]interface{}{
+		"id":     graphql.ID(projectID),
+		"first":  graphql.Int(pageSize),
+		"cursor": (*graphql.String)(nil),
+	}
+	for {
+		if err := c.gql.QueryWithContext(ctx, "ListProjectItems", &query, variables); err != nil {
+			return nil, apiError("list project items", err)
 		}
 
-		content, ok := itemMap["content"].(map[string]interface{})
-		if !ok {
-			continue
+		items := query.Node.ProjectV2.Items
+		for _, item := range items.Nodes {
+			// Pull requests and draft issues have no issue number.
+			issue := item.Content.Issue
+			if issue.Number == 0 {
+				continue
+			}
+			issues = append(issues, models.IssueItem{
+				ID:         item.ID,
+				Number:     issue.Number,
+				Title:      issue.Title,
+				Repository: issue.Repository.NameWithOwner,
+			})
 		}
 
-		if content["type"] != "Issue" {
-			continue
+		if !items.PageInfo.HasNextPage {
+			return issues, nil
 		}
+		variables["cursor"] = graphql.String(items.PageInfo.EndCursor)
+	}
+}
 
-		number, _ := content["number"].(float64)
-		title, _ := content["title"].(string)
-
-		var repoFullName string
-		if repo, ok := content["repository"].(map[string]interface{}); ok {
-			repoName, _ := repo["name"].(string)
-			if owner, ok := repo["owner"].(map[string]interface{}); ok {
-				ownerLogin, _ := owner["login"].(string)
-				repoFullName = fmt.Sprintf("%s/%s", ownerLogin, repoName)
-			}
-		}
+func (c *Client) GetProject(ctx context.Context, owner, projectNumber string) (*models.Project, error) {
+	owner, err := c.resolveOwner(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
 
-		if repoFullName == "" || number == 0 {
-			continue
-		}
+	number, err := strconv.Atoi(projectNumber)
+	if err != nil {
+		return nil, fmt.Errorf("invalid project number %q", projectNumber)
+	}
 
-		issues = append(issues, models.IssueItem{
-			Number:     int(number),
-			Title:      title,
-			Repository: repoFullName,
-		})
+	var query struct {
+		RepositoryOwner struct {
+			Typename string `graphql:"__typename"`
+			User     struct {
+				ProjectV2 models.Project `graphql:"projectV2(number: $number)"`
+			} `graphql:"... on User"`
+			Organization struct {
+				ProjectV2 models.Project `graphql:"projectV2(number: $number)"`
+			} `graphql:"... on Organization"`
+		} `graphql:"repositoryOwner(login: $owner)"`
+	}
+	if err := c.gql.QueryWithContext(ctx, "GetProject", &query, map[string]interface{}{
+		"owner":  graphql.String(owner),
+		"number": graphql.Int(number),
+	}); err != nil {
+		return nil, apiError(fmt.Sprintf("get project %s", projectNumber), err)
 	}
 
-	return issues, nil
+	project := query.RepositoryOwner.User.ProjectV2
+	if query.RepositoryOwner.Typename == "Organization" {
+		project = query.RepositoryOwner.Organization.ProjectV2
+	}
+	if project.ID == "" {
+		return nil, notFound(fmt.Sprintf("get project %s", projectNumber), fmt.Sprintf("%s has no project %s", owner, projectNumber))
+	}
+	c.cacheProjectID(owner, projectNumber, project.ID)
+	return &project, nil
 }
 
 func (c *Client) GetIssueTitle(ctx context.Context, repo string, issueNumber int) (string, error) {
-	cmd := exec.CommandContext(ctx, "gh", "issue", "view", fmt.Sprintf("%d", issueNumber), "--repo", repo, "--json", "title", "--jq", ".title")
-	output, err := cmd.Output()
+	issue, err := c.lookupIssue(ctx, repo, issueNumber)
 	if err != nil {
-		return "", fmt.Errorf("failed to get title for issue #%d: %w", issueNumber, err)
+		return "", err
 	}
-	return strings.TrimSpace(string(output)), nil
+	return issue.Title, nil
 }
 
-func (c *Client) DeleteProject(ctx context.Context, projectNumber string) error {
-	owner, err := c.GetUsername()
+func (c *Client) DeleteProject(ctx context.Context, owner, projectNumber string) error {
+	owner, err := c.resolveOwner(ctx, owner)
 	if err != nil {
-		return fmt.Errorf("failed to get GitHub username: %w", err)
+		return err
 	}
 
-	cmd := exec.CommandContext(ctx, "gh", "project", "delete", projectNumber, "--owner", owner)
-	output, err := cmd.CombinedOutput()
+	projectID, err := c.projectID(ctx, owner, projectNumber)
 	if err != nil {
-		return fmt.Errorf("failed to delete project: %s - %w", string(output), err)
+		return err
+	}
+
+	var mutation struct {
+		DeleteProjectV2 struct {
+			ClientMutationID string
+		} `graphql:"deleteProjectV2(input: $input)"`
 	}
+	if err := c.gql.MutateWithContext(ctx, "DeleteProject", &mutation, map[string]interface{}{
+		"input": DeleteProjectV2Input{ProjectID: graphql.ID(projectID)},
+	}); err != nil {
+		return apiError("delete project", err)
+	}
+	c.cacheProjectID(owner, projectNumber, "")
 	return nil
 }
 
-func (c *Client) LinkProjectToRepo(ctx context.Context, projectNumber, repoFullName string) error {
+// LinkProjectToRepo links a project to a repository. An empty owner means the
+// project belongs to the repository owner.
+func (c *Client) LinkProjectToRepo(ctx context.Context, owner, projectNumber, repoFullName string) error {
 	parts := strings.Split(repoFullName, "/")
 	if len(parts) != 2 {
 		return fmt.Errorf("invalid repository format: %s", repoFullName)
 	}
-	owner, repo := parts[0], parts[1]
+	if owner == "" {
+		owner = parts[0]
+	}
 
-	cmd := exec.CommandContext(ctx, "gh", "project", "link", projectNumber, "--owner", owner, "--repo", repo)
-	output, err := cmd.CombinedOutput()
+	projectID, err := c.projectID(ctx, owner, projectNumber)
 	if err != nil {
-		return fmt.Errorf("failed to link project to repository: %s - %w", string(output), err)
+		return err
+	}
+
+	var query struct {
+		Repository struct {
+			ID string
+		} `graphql:"repository(owner: $owner, name: $name)"`
+	}
+	if err := c.gql.QueryWithContext(ctx, "RepositoryID", &query, map[string]interface{}{
+		"owner": graphql.String(parts[0]),
+		"name":  graphql.String(parts[1]),
+	}); err != nil {
+		return apiError("look up repository "+repoFullName, err)
+	}
+
+	var mutation struct {
+		LinkProjectV2ToRepository struct {
+			ClientMutationID string
+		} `graphql:"linkProjectV2ToRepository(input: $input)"`
+	}
+	if err := c.gql.MutateWithContext(ctx, "LinkProject", &mutation, map[string]interface{}{
+		"input": LinkProjectV2ToRepositoryInput{ProjectID: graphql.ID(projectID), RepositoryID: graphql.ID(query.Repository.ID)},
+	}); err != nil {
+		return apiError("link project to repository", err)
 	}
 	return nil
 }
+
+// ListProjectRepos returns the owner/name of every repository a project is
+// linked to.
+func (c *Client) ListProjectRepos(ctx context.Context, owner, projectNumber string) ([]string, error) {
+	owner, err := c.resolveOwner(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	projectID, err := c.projectID(ctx, owner, projectNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	var query struct {
+		Node struct {
+			ProjectV2 struct {
+				Repositories struct {
+					Nodes []struct {
+						NameWithOwner string
+					}
+					PageInfo pageInfo
+				} `graphql:"repositories(first: $first, after: $cursor)"`
+			} `graphql:"... on ProjectV2"`
+		} `graphql:"node(id: $id)"`
+	}
+
+	var repos []string
+	variables := map[string]interface{}{
+		"id":     graphql.ID(projectID),
+		"first":  graphql.Int(pageSize),
+		"cursor": (*graphql.String)(nil),
+	}
+	for {
+		if err := c.gql.QueryWithContext(ctx, "ListProjectRepos", &query, variables); err != nil {
+			return nil, apiError("list project repositories", err)
+		}
+
+		page := query.Node.ProjectV2.Repositories
+		for _, repo := range page.Nodes {
+			repos = append(repos, repo.NameWithOwner)
+		}
+		if !page.PageInfo.HasNextPage {
+			return repos, nil
+		}
+		variables["cursor"] = graphql.String(page.PageInfo.EndCursor)
+	}
+}
+
+// projectID returns the node ID of a project, looking it up once per project.
+func (c *Client) projectID(ctx context.Context, owner, projectNumber string) (string, error) {
+	c.mu.Lock()
+	id := c.projectIDs[owner+"/"+projectNumber]
+	c.mu.Unlock()
+	if id != "" {
+		return id, nil
+	}
+
+	project, err := c.GetProject(ctx, owner, projectNumber)
+	if err != nil {
+		return "", err
+	}
+	return project.ID, nil
+}
+
+func (c *Client) cacheProjectID(owner, projectNumber, id string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if id == "" {
+		delete(c.projectIDs, owner+"/"+projectNumber)
+		return
+	}
+	c.projectIDs[owner+"/"+projectNumber] = id
+}
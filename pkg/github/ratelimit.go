@@ -0,0 +1,252 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// maxRetries is how many times a rate limited or failed call is retried.
+	maxRetries = 5
+	// baseBackoff is the first backoff delay; it doubles on every retry.
+	baseBackoff = time.Second
+	// maxBackoff caps the backoff delay when GitHub doesn't say how long to wait.
+	maxBackoff = time.Minute
+	// secondaryRateLimitWait is how long GitHub asks clients to wait after
+	// hitting a secondary rate limit without a Retry-After header.
+	secondaryRateLimitWait = time.Minute
+)
+
+// RateLimitHandler is told how long a call will wait for a rate limit to
+// reset, and is called again with 0 once the wait is over.
+type RateLimitHandler func(wait time.Duration)
+
+// rateLimiter holds the handler told about rate limit waits.
+type rateLimiter struct {
+	mu      sync.Mutex
+	handler RateLimitHandler
+}
+
+func (r *rateLimiter) setHandler(handler RateLimitHandler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.handler = handler
+}
+
+// wait sleeps for d, or until ctx is done, and reports the wait to the handler.
+func (r *rateLimiter) wait(ctx context.Context, d time.Duration) error {
+	r.mu.Lock()
+	handler := r.handler
+	r.mu.Unlock()
+
+	if handler != nil {
+		handler(d)
+		defer handler(0)
+	}
+
+	return sleep(ctx, d)
+}
+
+// sleep waits for d, or until ctx is done. Tests replace it to skip the wait.
+var sleep = func(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// rateLimitTransport retries requests that hit a primary or secondary rate
+// limit, waiting as long as the X-RateLimit-* and Retry-After headers say.
+// Rate limited requests were never carried out, so they are retried whatever
+// their method; server errors and network failures are only retried for
+// idempotent methods.
+//
+// timeout limits each attempt rather than the whole call, so waiting for a
+// rate limit to reset doesn't count against it.
+type rateLimitTransport struct {
+	base    http.RoundTripper
+	limiter *rateLimiter
+	timeout time.Duration
+}
+
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 && req.Body != nil {
+			if req.GetBody == nil {
+				return nil, fmt.Errorf("cannot retry %s %s: request body can't be replayed", req.Method, req.URL.Path)
+			}
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+
+		resp, err := t.attempt(req)
+		wait, retry := retryDelay(req, resp, err, attempt)
+		if !retry || attempt >= maxRetries {
+			return resp, err
+		}
+		if resp != nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		if err := t.limiter.wait(req.Context(), wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// attempt sends req once, giving up after t.timeout. The attempt's context
+// lives until the response body is closed, so the body can still be read.
+func (t *rateLimitTransport) attempt(req *http.Request) (*http.Response, error) {
+	if t.timeout <= 0 {
+		return t.base.RoundTrip(req)
+	}
+	ctx, cancel := context.WithTimeout(req.Context(), t.timeout)
+	resp, err := t.base.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// cancelOnClose cancels an attempt's context once its body is closed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// retryDelay decides whether a response should be retried and after how long.
+func retryDelay(req *http.Request, resp *http.Response, err error, attempt int) (time.Duration, bool) {
+	if err != nil {
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) || errors.Is(err, errNotRecorded) {
+			return 0, false
+		}
+		return backoff(attempt), isIdempotent(req.Method)
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusTooManyRequests:
+		if wait, ok := rateLimitWait(resp, time.Now()); ok {
+			return wait, true
+		}
+		if resp.StatusCode == http.StatusTooManyRequests || isSecondaryRateLimit(resp) {
+			return maxDuration(secondaryRateLimitWait, backoff(attempt)), true
+		}
+		return 0, false
+	case resp.StatusCode == http.StatusOK && strings.HasSuffix(req.URL.Path, "/graphql") && resp.Header.Get("X-RateLimit-Remaining") == "0":
+		// GraphQL reports an exhausted rate limit as a RATE_LIMITED error in
+		// a 200 response.
+		if isGraphQLRateLimited(resp) {
+			if wait, ok := rateLimitWait(resp, time.Now()); ok {
+				return wait, true
+			}
+		}
+		return 0, false
+	case resp.StatusCode >= 500:
+		return backoff(attempt), isIdempotent(req.Method)
+	}
+	return 0, false
+}
+
+// rateLimitWait reads how long to wait from the Retry-After header, or from
+// X-RateLimit-Reset when the primary rate limit is used up.
+func rateLimitWait(resp *http.Response, now time.Time) (time.Duration, bool) {
+	if retryAfter := resp.Header.Get("Retry-After"); retryAfter != "" {
+		if seconds, err := strconv.Atoi(retryAfter); err == nil {
+			return jitter(time.Duration(seconds) * time.Second), true
+		}
+		if at, err := http.ParseTime(retryAfter); err == nil {
+			return jitter(maxDuration(at.Sub(now), 0)), true
+		}
+	}
+
+	if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+		if reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+			return jitter(maxDuration(time.Unix(reset, 0).Sub(now), 0)), true
+		}
+	}
+	return 0, false
+}
+
+var (
+	secondaryRateLimitPattern = regexp.MustCompile(`(?i)secondary rate limit|submitted too quickly|abuse detection`)
+	graphQLRateLimitPattern   = regexp.MustCompile(`"type"\s*:\s*"RATE_LIMITED"`)
+)
+
+// isSecondaryRateLimit reports whether a 403 is a secondary rate limit, which
+// GitHub only signals in the error message.
+func isSecondaryRateLimit(resp *http.Response) bool {
+	return bodyMatches(resp, secondaryRateLimitPattern)
+}
+
+func isGraphQLRateLimited(resp *http.Response) bool {
+	return bodyMatches(resp, graphQLRateLimitPattern)
+}
+
+// bodyMatches reports whether the response body matches pattern, leaving the
+// body readable for the caller.
+func bodyMatches(resp *http.Response, pattern *regexp.Regexp) bool {
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return false
+	}
+	return pattern.Match(body)
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// backoff returns an exponential delay with jitter for the given attempt.
+func backoff(attempt int) time.Duration {
+	d := baseBackoff << attempt
+	if d > maxBackoff || d <= 0 {
+		d = maxBackoff
+	}
+	return jitter(d)
+}
+
+// jitter adds up to a second, or a tenth of d if that's more, so that
+// concurrent callers don't all retry at the same moment.
+func jitter(d time.Duration) time.Duration {
+	spread := maxDuration(d/10, time.Second)
+	return d + time.Duration(rand.Int63n(int64(spread)))
+}
+
+func maxDuration(a, b time.Duration) time.Duration {
+	if a > b {
+		return a
+	}
+	return b
+}
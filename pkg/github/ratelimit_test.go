@@ -0,0 +1,236 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// roundTripFunc is an http.RoundTripper that calls itself.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func response(status int, header http.Header, body string) *http.Response {
+	if header == nil {
+		header = http.Header{}
+	}
+	return &http.Response{StatusCode: status, Header: header, Body: io.NopCloser(strings.NewReader(body))}
+}
+
+// skipSleep makes rate limit and backoff waits return at once, and returns
+// the waits that were asked for.
+func skipSleep(t *testing.T) func() []time.Duration {
+	var mu sync.Mutex
+	var waits []time.Duration
+	old := sleep
+	sleep = func(ctx context.Context, d time.Duration) error {
+		mu.Lock()
+		defer mu.Unlock()
+		waits = append(waits, d)
+		return ctx.Err()
+	}
+	t.Cleanup(func() { sleep = old })
+	return func() []time.Duration {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]time.Duration{}, waits...)
+	}
+}
+
+func TestTimeoutLimitsEachAttempt(t *testing.T) {
+	skipSleep(t)
+	calls := 0
+	slow := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		calls++
+		select {
+		case <-time.After(30 * time.Millisecond):
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+		if calls < 3 {
+			return response(http.StatusServiceUnavailable, nil, ""), nil
+		}
+		return response(http.StatusOK, nil, "done"), nil
+	})
+	transport := &rateLimitTransport{base: slow, limiter: &rateLimiter{}, timeout: 50 * time.Millisecond}
+
+	req, _ := http.NewRequest(http.MethodGet, "https://api.github.com/user", nil)
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("three attempts of 30ms with a 50ms timeout: %v", err)
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil || string(body) != "done" {
+		t.Errorf("body = %q, %v; want done", body, err)
+	}
+
+	transport.timeout = 10 * time.Millisecond
+	calls = 0
+	if _, err := transport.RoundTrip(req); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("a 30ms attempt with a 10ms timeout: err = %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	now := time.Now()
+	reset := func(in time.Duration) string { return strconv.FormatInt(now.Add(in).Unix(), 10) }
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		resp     *http.Response
+		err      error
+		retry    bool
+		min, max time.Duration
+	}{
+		{
+			name: "Retry-After seconds", resp: response(http.StatusTooManyRequests, http.Header{"Retry-After": {"30"}}, ""),
+			retry: true, min: 30 * time.Second, max: 33 * time.Second,
+		},
+		{
+			name: "Retry-After date", resp: response(http.StatusForbidden, http.Header{"Retry-After": {now.Add(2 * time.Minute).UTC().Format(http.TimeFormat)}}, ""),
+			retry: true, min: 118 * time.Second, max: 133 * time.Second,
+		},
+		{
+			name:  "primary limit used up",
+			resp:  response(http.StatusForbidden, http.Header{"X-Ratelimit-Remaining": {"0"}, "X-Ratelimit-Reset": {reset(2 * time.Minute)}}, `{"message": "API rate limit exceeded"}`),
+			retry: true, min: 118 * time.Second, max: 133 * time.Second,
+		},
+		{
+			name:  "secondary limit",
+			resp:  response(http.StatusForbidden, nil, `{"message": "You have exceeded a secondary rate limit."}`),
+			retry: true, min: secondaryRateLimitWait, max: secondaryRateLimitWait + 7*time.Second,
+		},
+		{
+			name: "forbidden", resp: response(http.StatusForbidden, nil, `{"message": "Resource not accessible by integration"}`),
+		},
+		{
+			name: "GraphQL RATE_LIMITED", path: "/graphql",
+			resp:  response(http.StatusOK, http.Header{"X-Ratelimit-Remaining": {"0"}, "X-Ratelimit-Reset": {reset(time.Minute)}}, `{"errors": [{"type": "RATE_LIMITED", "message": "API rate limit exceeded"}]}`),
+			retry: true, min: 58 * time.Second, max: 67 * time.Second,
+		},
+		{
+			name: "GraphQL with the limit used up by this call", path: "/graphql",
+			resp: response(http.StatusOK, http.Header{"X-Ratelimit-Remaining": {"0"}, "X-Ratelimit-Reset": {reset(time.Minute)}}, `{"data": {}}`),
+		},
+		{
+			name: "server error on GET", resp: response(http.StatusBadGateway, nil, ""),
+			retry: true, min: baseBackoff, max: baseBackoff + time.Second,
+		},
+		{
+			name: "server error on POST", method: http.MethodPost, resp: response(http.StatusBadGateway, nil, ""),
+		},
+		{
+			name: "network error on GET", err: errors.New("connection reset"),
+			retry: true, min: baseBackoff, max: baseBackoff + time.Second,
+		},
+		{
+			name: "cancelled", err: context.Canceled,
+		},
+		{
+			name: "not recorded", err: errNotRecorded,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			method, path := tt.method, tt.path
+			if method == "" {
+				method = http.MethodGet
+			}
+			if path == "" {
+				path = "/repos/acme/api/issues"
+			}
+			req, _ := http.NewRequest(method, "https://api.github.com"+path, nil)
+
+			wait, retry := retryDelay(req, tt.resp, tt.err, 0)
+			if retry != tt.retry {
+				t.Fatalf("retry = %v, want %v", retry, tt.retry)
+			}
+			if retry && (wait < tt.min || wait >= tt.max) {
+				t.Errorf("wait = %v, want between %v and %v", wait, tt.min, tt.max)
+			}
+		})
+	}
+}
+
+func TestBackoffDoublesUpToMax(t *testing.T) {
+	for attempt, want := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second} {
+		if got := backoff(attempt); got < want || got >= want+time.Second {
+			t.Errorf("backoff(%d) = %v, want %v plus up to a second", attempt, got, want)
+		}
+	}
+	if got := backoff(20); got < maxBackoff || got >= maxBackoff+maxBackoff/10 {
+		t.Errorf("backoff(20) = %v, want %v plus jitter", got, maxBackoff)
+	}
+}
+
+func TestRateLimitTransportRetries(t *testing.T) {
+	waits := skipSleep(t)
+	var bodies []string
+	calls := 0
+	limited := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		calls++
+		body, _ := io.ReadAll(req.Body)
+		bodies = append(bodies, string(body))
+		if calls == 1 {
+			return response(http.StatusTooManyRequests, http.Header{"Retry-After": {"5"}}, ""), nil
+		}
+		return response(http.StatusCreated, nil, `{"number": 1}`), nil
+	})
+	var reported []time.Duration
+	limiter := &rateLimiter{}
+	limiter.setHandler(func(wait time.Duration) { reported = append(reported, wait) })
+	transport := &rateLimitTransport{base: limited, limiter: limiter}
+
+	req, _ := http.NewRequest(http.MethodPost, "https://api.github.com/repos/acme/api/issues", strings.NewReader(`{"title": "T"}`))
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated || calls != 2 {
+		t.Fatalf("got %d after %d calls, want 201 after 2", resp.StatusCode, calls)
+	}
+	if bodies[1] != bodies[0] {
+		t.Errorf("retried body = %q, want %q", bodies[1], bodies[0])
+	}
+	if w := waits(); len(w) != 1 || w[0] < 5*time.Second {
+		t.Errorf("waits = %v, want one of at least 5s", w)
+	}
+	if len(reported) != 2 || reported[0] < 5*time.Second || reported[1] != 0 {
+		t.Errorf("handler was told %v, want the wait and then 0", reported)
+	}
+}
+
+func TestRateLimitTransportGivesUp(t *testing.T) {
+	waits := skipSleep(t)
+	calls := 0
+	limited := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		calls++
+		return response(http.StatusTooManyRequests, http.Header{"Retry-After": {"1"}}, ""), nil
+	})
+	transport := &rateLimitTransport{base: limited, limiter: &rateLimiter{}}
+
+	req, _ := http.NewRequest(http.MethodGet, "https://api.github.com/user", nil)
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("status = %d, want the last 429", resp.StatusCode)
+	}
+	if calls != maxRetries+1 || len(waits()) != maxRetries {
+		t.Errorf("%d calls and %d waits, want %d and %d", calls, len(waits()), maxRetries+1, maxRetries)
+	}
+}
@@ -1,32 +1,67 @@
 package models
 
-import "time"
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
 
 type Issue struct {
-	Title  string `json:"title"`
-	Body   string `json:"body"`
-	Number int    `json:"number,omitempty"`
+	Key       string   `json:"key,omitempty" yaml:"key,omitempty" toml:"key,omitempty" jsonschema:"pattern=^[A-Za-z0-9._/-]+$"`
+	Title     string   `json:"title" yaml:"title" toml:"title" jsonschema:"required,minLength=1,maxLength=256"`
+	Body      string   `json:"body" yaml:"body" toml:"body" jsonschema:"maxLength=65536"`
+	Labels    []string `json:"labels,omitempty" yaml:"labels,omitempty" toml:"labels,omitempty"`
+	Assignees []string `json:"assignees,omitempty" yaml:"assignees,omitempty" toml:"assignees,omitempty"`
+	Type      string   `json:"type,omitempty" yaml:"type,omitempty" toml:"type,omitempty"`
+	Number    int      `json:"number,omitempty" yaml:"number,omitempty" toml:"number,omitempty"`
+}
+
+type Label struct {
+	Name        string `json:"name" yaml:"name" toml:"name" jsonschema:"required,minLength=1,maxLength=50"`
+	Color       string `json:"color,omitempty" yaml:"color,omitempty" toml:"color,omitempty" jsonschema:"pattern=^#?[0-9a-fA-F]{6}$"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty" toml:"description,omitempty" jsonschema:"maxLength=100"`
 }
 
 type Milestone struct {
-	Title       string    `json:"title"`
-	Description string    `json:"description,omitempty"`
-	DueOn       time.Time `json:"due_on,omitempty"`
-	State       string    `json:"state,omitempty"`
-	Number      int       `json:"number,omitempty"`
+	Key         string `json:"key,omitempty" yaml:"key,omitempty" toml:"key,omitempty" jsonschema:"pattern=^[A-Za-z0-9._/-]+$"`
+	Title       string `json:"title" yaml:"title" toml:"title" jsonschema:"required,minLength=1"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty" toml:"description,omitempty" jsonschema:"maxLength=65536"`
+	DueOn       Date   `json:"due_on,omitempty" yaml:"due_on,omitempty" toml:"due_on,omitempty"`
+	State       string `json:"state,omitempty" yaml:"state,omitempty" toml:"state,omitempty" jsonschema:"enum=open|closed"`
+	Number      int    `json:"number,omitempty" yaml:"number,omitempty" toml:"number,omitempty"`
 }
 
 type MilestoneWithIssues struct {
-	Milestone
-	Issues []Issue `json:"issues"`
+	Milestone `yaml:",inline"`
+	Issues    []Issue `json:"issues" yaml:"issues" toml:"issues"`
 }
 
 type TasksFile struct {
-	ProjectTitle string                `json:"projectTitle"`
-	Milestones   []MilestoneWithIssues `json:"milestones"`
+	ProjectTitle string                `json:"projectTitle" yaml:"projectTitle" toml:"projectTitle" jsonschema:"required,minLength=1"`
+	Owner        string                `json:"owner,omitempty" yaml:"owner,omitempty" toml:"owner,omitempty"`
+	Labels       []Label               `json:"labels,omitempty" yaml:"labels,omitempty" toml:"labels,omitempty"`
+	Milestones   []MilestoneWithIssues `json:"milestones" yaml:"milestones" toml:"milestones"`
+}
+
+// RepoIssue is an issue as returned by the repository issues API.
+type RepoIssue struct {
+	Number      int        `json:"number"`
+	Title       string     `json:"title"`
+	Body        string     `json:"body"`
+	State       string     `json:"state"`
+	Milestone   *Milestone `json:"milestone"`
+	Labels      []Label    `json:"labels"`
+	Assignees   []User     `json:"assignees"`
+	PullRequest *struct{}  `json:"pull_request,omitempty"`
+}
+
+type User struct {
+	Login string `json:"login"`
 }
 
 type IssueItem struct {
+	ID         string `json:"id"`
 	Number     int    `json:"number"`
 	Repository string `json:"repository"`
 	Title      string `json:"title"`
@@ -41,7 +76,47 @@ type Project struct {
 	ShortDescription string `json:"shortDescription"`
 }
 
-type ProjectListResponse struct {
-	Projects   []Project `json:"projects"`
-	TotalCount int       `json:"totalCount"`
+// Date is a milestone due date. Tasks files may give it as an RFC 3339
+// timestamp or a plain YYYY-MM-DD date, quoted or, in YAML and TOML, bare.
+// It is written out as RFC 3339, as GitHub expects.
+type Date struct {
+	time.Time
+}
+
+// dateLayouts are the forms ParseDate accepts, including TOML's local date
+// times, which have no offset and may use a space instead of T.
+var dateLayouts = []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02 15:04:05", "2006-01-02"}
+
+// ParseDate parses a due date in any form a tasks file may use. Dates without
+// an offset are taken as UTC.
+func ParseDate(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid due date %q; expected YYYY-MM-DD or RFC 3339", value)
+}
+
+// UnmarshalText is used by YAML and TOML for quoted and bare dates alike.
+func (d *Date) UnmarshalText(text []byte) error {
+	t, err := ParseDate(string(text))
+	if err != nil {
+		return err
+	}
+	d.Time = t
+	return nil
+}
+
+func (d *Date) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		d.Time = time.Time{}
+		return nil
+	}
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		return fmt.Errorf("due date must be a string: %w", err)
+	}
+	return d.UnmarshalText([]byte(text))
 }
@@ -0,0 +1,120 @@
+package schema
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/igorcosta/gh-lazy/pkg/models"
+)
+
+const (
+	draftURL = "https://json-schema.org/draft/2020-12/schema"
+	schemaID = "https://github.com/igorcosta/gh-lazy/schema/tasks.schema.json"
+)
+
+// Schema is the subset of JSON Schema used to describe tasks files.
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	ID                   string             `json:"$id,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *bool              `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+}
+
+var (
+	timeType = reflect.TypeOf(time.Time{})
+	dateType = reflect.TypeOf(models.Date{})
+)
+
+// TasksFile returns the JSON Schema for models.TasksFile, generated from its
+// json and jsonschema struct tags.
+func TasksFile() *Schema {
+	s := generate(reflect.TypeOf(models.TasksFile{}))
+	s.Schema = draftURL
+	s.ID = schemaID
+	s.Title = "gh-lazy tasks file"
+	return s
+}
+
+func generate(t reflect.Type) *Schema {
+	if t == timeType || t == dateType {
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return generate(t.Elem())
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: generate(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object"}
+	case reflect.Struct:
+		closed := false
+		s := &Schema{Type: "object", Properties: map[string]*Schema{}, AdditionalProperties: &closed}
+		addFields(s, t)
+		return s
+	default:
+		return &Schema{}
+	}
+}
+
+func addFields(s *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+
+		if field.Anonymous && name == "" {
+			addFields(s, field.Type)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		property := generate(field.Type)
+		for _, option := range strings.Split(field.Tag.Get("jsonschema"), ",") {
+			key, value, _ := strings.Cut(option, "=")
+			switch key {
+			case "required":
+				s.Required = append(s.Required, name)
+			case "minLength":
+				n, _ := strconv.Atoi(value)
+				property.MinLength = &n
+			case "maxLength":
+				n, _ := strconv.Atoi(value)
+				property.MaxLength = &n
+			case "pattern":
+				property.Pattern = value
+			case "enum":
+				property.Enum = strings.Split(value, "|")
+			}
+		}
+		s.Properties[name] = property
+	}
+}
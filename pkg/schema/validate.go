@@ -0,0 +1,327 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/igorcosta/gh-lazy/pkg/models"
+	"github.com/igorcosta/gh-lazy/pkg/utils"
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// Problem is a single validation finding, located by its path in the tasks
+// file (e.g. milestones[2].issues[5].title).
+type Problem struct {
+	Path    string
+	Message string
+	Warning bool
+}
+
+func (p Problem) String() string {
+	if p.Path == "" {
+		return p.Message
+	}
+	return fmt.Sprintf("%s: %s", p.Path, p.Message)
+}
+
+// HasErrors reports whether any of the problems is an error rather than a warning.
+func HasErrors(problems []Problem) bool {
+	for _, p := range problems {
+		if !p.Warning {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateFile checks a tasks file against the TasksFile schema and the rules
+// GitHub enforces on create. An empty format is detected from the file extension.
+func ValidateFile(filePath, format string) ([]Problem, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("reading tasks file: %w", err)
+	}
+
+	if format == "" {
+		format, err = utils.DetectTasksFormat(filePath)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	doc, err := decode(data, format)
+	if err != nil {
+		return nil, err
+	}
+
+	return Validate(doc, time.Now()), nil
+}
+
+// Validate checks a generically decoded tasks document. Due dates before now
+// are reported as warnings.
+func Validate(doc interface{}, now time.Time) []Problem {
+	var problems []Problem
+	walk(doc, TasksFile(), "", &problems)
+	problems = append(problems, checkIssueTitles(doc)...)
+	problems = append(problems, checkKeys(doc)...)
+	problems = append(problems, checkDueDates(doc, now)...)
+	return problems
+}
+
+func decode(data []byte, format string) (interface{}, error) {
+	var doc interface{}
+	switch strings.ToLower(format) {
+	case utils.FormatJSON:
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("parsing tasks JSON: %w", err)
+		}
+	case utils.FormatYAML, "yml":
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("parsing tasks YAML: %w", err)
+		}
+	case utils.FormatTOML:
+		if err := toml.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("parsing tasks TOML: %w", err)
+		}
+	case utils.FormatMarkdown, "md":
+		tasks, err := utils.ParseMarkdownTasks(data)
+		if err != nil {
+			return nil, fmt.Errorf("parsing tasks Markdown: %w", err)
+		}
+		// Round-trip through JSON so Markdown outlines share the generic checks.
+		encoded, err := json.Marshal(tasks)
+		if err != nil {
+			return nil, fmt.Errorf("encoding tasks: %w", err)
+		}
+		if err := json.Unmarshal(encoded, &doc); err != nil {
+			return nil, fmt.Errorf("decoding tasks: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported tasks file format %q. Expected one of: json, yaml, toml, markdown", format)
+	}
+	return doc, nil
+}
+
+func walk(value interface{}, s *Schema, path string, problems *[]Problem) {
+	report := func(format string, args ...interface{}) {
+		*problems = append(*problems, Problem{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	switch s.Type {
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			report("must be an object")
+			return
+		}
+		for _, name := range s.Required {
+			if v, ok := object[name]; !ok || v == nil {
+				*problems = append(*problems, Problem{Path: joinPath(path, name), Message: "is required"})
+			}
+		}
+		keys := make([]string, 0, len(object))
+		for key := range object {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			property, ok := s.Properties[key]
+			if !ok {
+				if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+					*problems = append(*problems, Problem{Path: joinPath(path, key), Message: unknownKeyMessage(key, s)})
+				}
+				continue
+			}
+			if object[key] == nil {
+				continue
+			}
+			walk(object[key], property, joinPath(path, key), problems)
+		}
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			report("must be an array")
+			return
+		}
+		for i, item := range items {
+			walk(item, s.Items, fmt.Sprintf("%s[%d]", path, i), problems)
+		}
+	case "string":
+		if s.Format == "date-time" {
+			if _, ok := asTime(value); !ok {
+				report("invalid date %v; expected YYYY-MM-DD or RFC 3339 (e.g. 2024-12-31T23:59:59Z)", value)
+			}
+			return
+		}
+		str, ok := value.(string)
+		if !ok {
+			report("must be a string")
+			return
+		}
+		length := utf8.RuneCountInString(strings.TrimSpace(str))
+		if s.MinLength != nil && length < *s.MinLength {
+			report("must not be empty")
+		}
+		if s.MaxLength != nil && utf8.RuneCountInString(str) > *s.MaxLength {
+			report("is %d characters long; GitHub allows at most %d", utf8.RuneCountInString(str), *s.MaxLength)
+		}
+		if s.Pattern != "" && !regexp.MustCompile(s.Pattern).MatchString(str) {
+			report("must match %s", s.Pattern)
+		}
+		if len(s.Enum) > 0 && !contains(s.Enum, str) {
+			report("must be one of: %s", strings.Join(s.Enum, ", "))
+		}
+	case "integer":
+		if !isInteger(value) {
+			report("must be an integer")
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			report("must be a boolean")
+		}
+	}
+}
+
+func checkIssueTitles(doc interface{}) []Problem {
+	var problems []Problem
+	seen := map[string]string{}
+	forEachMilestone(doc, func(milestonePath string, milestone map[string]interface{}) {
+		issues, _ := milestone["issues"].([]interface{})
+		for i, item := range issues {
+			issue, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			title, ok := issue["title"].(string)
+			if !ok || strings.TrimSpace(title) == "" {
+				continue
+			}
+			path := fmt.Sprintf("%s.issues[%d].title", milestonePath, i)
+			if first, ok := seen[title]; ok {
+				problems = append(problems, Problem{Path: path, Message: fmt.Sprintf("duplicate issue title %q (first defined at %s)", title, first)})
+				continue
+			}
+			seen[title] = path
+		}
+	})
+	return problems
+}
+
+func checkKeys(doc interface{}) []Problem {
+	var problems []Problem
+	seen := map[string]string{}
+	check := func(path string, item map[string]interface{}) {
+		key, ok := item["key"].(string)
+		if !ok || key == "" {
+			return
+		}
+		path += ".key"
+		if first, ok := seen[key]; ok {
+			problems = append(problems, Problem{Path: path, Message: fmt.Sprintf("duplicate key %q (first defined at %s)", key, first)})
+			return
+		}
+		seen[key] = path
+	}
+	forEachMilestone(doc, func(milestonePath string, milestone map[string]interface{}) {
+		check(milestonePath, milestone)
+		issues, _ := milestone["issues"].([]interface{})
+		for i, item := range issues {
+			if issue, ok := item.(map[string]interface{}); ok {
+				check(fmt.Sprintf("%s.issues[%d]", milestonePath, i), issue)
+			}
+		}
+	})
+	return problems
+}
+
+func checkDueDates(doc interface{}, now time.Time) []Problem {
+	var problems []Problem
+	forEachMilestone(doc, func(milestonePath string, milestone map[string]interface{}) {
+		dueOn, ok := asTime(milestone["due_on"])
+		if !ok || dueOn.IsZero() || !dueOn.Before(now) {
+			return
+		}
+		problems = append(problems, Problem{
+			Path:    milestonePath + ".due_on",
+			Message: fmt.Sprintf("due date %s is in the past", dueOn.Format(time.RFC3339)),
+			Warning: true,
+		})
+	})
+	return problems
+}
+
+func forEachMilestone(doc interface{}, fn func(path string, milestone map[string]interface{})) {
+	root, ok := doc.(map[string]interface{})
+	if !ok {
+		return
+	}
+	milestones, _ := root["milestones"].([]interface{})
+	for i, item := range milestones {
+		if milestone, ok := item.(map[string]interface{}); ok {
+			fn(fmt.Sprintf("milestones[%d]", i), milestone)
+		}
+	}
+}
+
+func asTime(value interface{}) (time.Time, bool) {
+	switch v := value.(type) {
+	case time.Time:
+		return v, true
+	case toml.LocalDateTime:
+		return v.AsTime(time.UTC), true
+	case toml.LocalDate:
+		return v.AsTime(time.UTC), true
+	case string:
+		t, err := models.ParseDate(v)
+		return t, err == nil
+	}
+	return time.Time{}, false
+}
+
+func isInteger(value interface{}) bool {
+	switch v := value.(type) {
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return true
+	case float64:
+		return v == math.Trunc(v)
+	}
+	return false
+}
+
+func unknownKeyMessage(key string, s *Schema) string {
+	normalized := normalizeKey(key)
+	for name := range s.Properties {
+		if normalizeKey(name) == normalized {
+			return fmt.Sprintf("unknown key (did you mean %q?)", name)
+		}
+	}
+	return "unknown key"
+}
+
+func normalizeKey(key string) string {
+	return strings.ToLower(strings.NewReplacer("_", "", "-", "").Replace(key))
+}
+
+func joinPath(parent, key string) string {
+	if parent == "" {
+		return key
+	}
+	return parent + "." + key
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,39 @@
+package schema
+
+import (
+	"testing"
+	"time"
+)
+
+func TestValidateAcceptsDueDateForms(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := map[string]struct{ format, data string }{
+		"json date":        {"json", `{"projectTitle": "P", "milestones": [{"title": "M", "due_on": "2024-06-30", "issues": []}]}`},
+		"yaml bare date":   {"yaml", "projectTitle: P\nmilestones:\n  - title: M\n    due_on: 2024-06-30\n    issues: []\n"},
+		"toml quoted date": {"toml", "projectTitle = \"P\"\n[[milestones]]\ntitle = \"M\"\ndue_on = \"2024-06-30\"\nissues = []\n"},
+		"toml bare date":   {"toml", "projectTitle = \"P\"\n[[milestones]]\ntitle = \"M\"\ndue_on = 2024-06-30\nissues = []\n"},
+	}
+	for name, tt := range tests {
+		doc, err := decode([]byte(tt.data), tt.format)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if problems := Validate(doc, now); len(problems) > 0 {
+			t.Errorf("%s: unexpected problems: %v", name, problems)
+		}
+	}
+}
+
+func TestValidateRejectsBadDueDate(t *testing.T) {
+	doc, err := decode([]byte(`{"projectTitle": "P", "milestones": [{"title": "M", "due_on": "June 30", "issues": []}]}`), "json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	problems := Validate(doc, time.Now())
+	if !HasErrors(problems) {
+		t.Fatalf("expected an error for an unparseable due date, got %v", problems)
+	}
+	if got := problems[0].Path; got != "milestones[0].due_on" {
+		t.Errorf("problem path = %q, want milestones[0].due_on", got)
+	}
+}
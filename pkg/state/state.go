@@ -0,0 +1,130 @@
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DefaultFile is where create records its progress unless told otherwise.
+const DefaultFile = ".lazy-state.json"
+
+// State records what a create run produced so that it can be resumed.
+type State struct {
+	Repo          string                     `json:"repo"`
+	ProjectTitle  string                     `json:"projectTitle"`
+	ProjectURL    string                     `json:"projectURL,omitempty"`
+	ProjectLinked bool                       `json:"projectLinked,omitempty"`
+	Milestones    map[string]*MilestoneState `json:"milestones"`
+	Issues        map[string]*IssueState     `json:"issues"`
+	Completed     bool                       `json:"completed"`
+	UpdatedAt     time.Time                  `json:"updatedAt"`
+
+	path string
+}
+
+type MilestoneState struct {
+	Title  string `json:"title"`
+	Number int    `json:"number"`
+}
+
+type IssueState struct {
+	Title         string `json:"title"`
+	Number        int    `json:"number"`
+	URL           string `json:"url,omitempty"`
+	Milestone     int    `json:"milestone,omitempty"`
+	ProjectItemID string `json:"projectItemID,omitempty"`
+}
+
+// TaskID identifies a milestone or issue in the state file by its key, or by
+// its title when it has none.
+func TaskID(key, title string) string {
+	if key != "" {
+		return "key:" + key
+	}
+	return "title:" + title
+}
+
+// New returns an empty state that will be saved to path.
+func New(path, repo, projectTitle string) *State {
+	return &State{
+		Repo:         repo,
+		ProjectTitle: projectTitle,
+		Milestones:   map[string]*MilestoneState{},
+		Issues:       map[string]*IssueState{},
+		path:         path,
+	}
+}
+
+// Load reads the state file at path. It returns nil and no error if the file
+// does not exist.
+func Load(path string) (*State, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading state file: %w", err)
+	}
+
+	var s State
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("parsing state file %s: %w", path, err)
+	}
+	if s.Milestones == nil {
+		s.Milestones = map[string]*MilestoneState{}
+	}
+	if s.Issues == nil {
+		s.Issues = map[string]*IssueState{}
+	}
+	s.path = path
+	return &s, nil
+}
+
+// Save writes the state atomically, so an interrupted run never leaves a
+// truncated file behind.
+func (s *State) Save() error {
+	s.UpdatedAt = time.Now().UTC()
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding state: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), ".lazy-state-*.json")
+	if err != nil {
+		return fmt.Errorf("writing state file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return fmt.Errorf("writing state file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("writing state file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("writing state file: %w", err)
+	}
+	return nil
+}
+
+// Milestone returns the recorded state for a milestone, creating it if needed.
+func (s *State) Milestone(key, title string) *MilestoneState {
+	id := TaskID(key, title)
+	if s.Milestones[id] == nil {
+		s.Milestones[id] = &MilestoneState{Title: title}
+	}
+	return s.Milestones[id]
+}
+
+// Issue returns the recorded state for an issue, creating it if needed.
+func (s *State) Issue(key, title string) *IssueState {
+	id := TaskID(key, title)
+	if s.Issues[id] == nil {
+		s.Issues[id] = &IssueState{Title: title}
+	}
+	return s.Issues[id]
+}
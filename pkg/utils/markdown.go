@@ -0,0 +1,129 @@
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/igorcosta/gh-lazy/pkg/models"
+)
+
+// MarkdownParseError reports a problem in a Markdown tasks outline.
+type MarkdownParseError struct {
+	Line int
+	Msg  string
+}
+
+func (e *MarkdownParseError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Msg)
+}
+
+// ParseMarkdownTasks builds a tasks file from a Markdown outline:
+//
+//	# Project title
+//	## Milestone title
+//	due: 2024-12-31
+//	Optional milestone description.
+//	- Issue title
+//	  Indented issue body.
+func ParseMarkdownTasks(data []byte) (*models.TasksFile, error) {
+	var tasks models.TasksFile
+	var milestone *models.MilestoneWithIssues
+	var issue *models.Issue
+	var description, body []string
+	bodyIndent := ""
+
+	flushIssue := func() {
+		if issue == nil {
+			return
+		}
+		issue.Body = strings.TrimSpace(strings.Join(body, "\n"))
+		milestone.Issues = append(milestone.Issues, *issue)
+		issue, body, bodyIndent = nil, nil, ""
+	}
+	flushMilestone := func() {
+		flushIssue()
+		if milestone == nil {
+			return
+		}
+		milestone.Description = strings.TrimSpace(strings.Join(description, "\n"))
+		tasks.Milestones = append(tasks.Milestones, *milestone)
+		milestone, description = nil, nil
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		trimmed := strings.TrimSpace(line)
+
+		if issue != nil && (trimmed == "" || line[0] == ' ' || line[0] == '\t') {
+			if trimmed != "" && bodyIndent == "" {
+				bodyIndent = line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+			}
+			body = append(body, strings.TrimPrefix(line, bodyIndent))
+			continue
+		}
+
+		switch {
+		case trimmed == "":
+			if milestone != nil && len(description) > 0 {
+				description = append(description, "")
+			}
+		case strings.HasPrefix(line, "# "):
+			if tasks.ProjectTitle != "" {
+				return nil, &MarkdownParseError{lineNumber, "duplicate project title; only one '#' heading is allowed"}
+			}
+			if milestone != nil {
+				return nil, &MarkdownParseError{lineNumber, "project title must come before the first milestone"}
+			}
+			tasks.ProjectTitle = strings.TrimSpace(line[2:])
+		case strings.HasPrefix(line, "## "):
+			flushMilestone()
+			title := strings.TrimSpace(line[3:])
+			if title == "" {
+				return nil, &MarkdownParseError{lineNumber, "milestone heading has no title"}
+			}
+			milestone = &models.MilestoneWithIssues{Milestone: models.Milestone{Title: title}}
+		case strings.HasPrefix(line, "#"):
+			return nil, &MarkdownParseError{lineNumber, "unsupported heading; use '#' for the project and '##' for milestones"}
+		case strings.HasPrefix(line, "- ") || strings.HasPrefix(line, "* "):
+			if milestone == nil {
+				return nil, &MarkdownParseError{lineNumber, "issue appears before any '##' milestone heading"}
+			}
+			flushIssue()
+			title := strings.TrimSpace(line[2:])
+			if title == "" {
+				return nil, &MarkdownParseError{lineNumber, "issue bullet has no title"}
+			}
+			issue = &models.Issue{Title: title}
+		case milestone == nil:
+			return nil, &MarkdownParseError{lineNumber, "text appears before any '##' milestone heading"}
+		case len(milestone.Issues) > 0 || issue != nil:
+			return nil, &MarkdownParseError{lineNumber, "unexpected text after issues; indent issue body lines under their '-' bullet"}
+		case strings.HasPrefix(strings.ToLower(trimmed), "due:"):
+			if !milestone.DueOn.IsZero() {
+				return nil, &MarkdownParseError{lineNumber, "milestone has more than one 'due:' line"}
+			}
+			dueOn, err := models.ParseDate(trimmed[len("due:"):])
+			if err != nil {
+				return nil, &MarkdownParseError{lineNumber, err.Error()}
+			}
+			milestone.DueOn = models.Date{Time: dueOn}
+		default:
+			description = append(description, trimmed)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading Markdown tasks: %w", err)
+	}
+	flushMilestone()
+
+	if tasks.ProjectTitle == "" {
+		return nil, &MarkdownParseError{1, "missing '#' project title"}
+	}
+
+	return &tasks, nil
+}
@@ -0,0 +1,84 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/igorcosta/gh-lazy/pkg/models"
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// Supported tasks file formats.
+const (
+	FormatJSON     = "json"
+	FormatYAML     = "yaml"
+	FormatTOML     = "toml"
+	FormatMarkdown = "markdown"
+)
+
+// DetectTasksFormat returns the tasks file format implied by the file extension.
+func DetectTasksFormat(filePath string) (string, error) {
+	switch strings.ToLower(filepath.Ext(filePath)) {
+	case ".json":
+		return FormatJSON, nil
+	case ".yaml", ".yml":
+		return FormatYAML, nil
+	case ".toml":
+		return FormatTOML, nil
+	case ".md", ".markdown":
+		return FormatMarkdown, nil
+	default:
+		return "", fmt.Errorf("unsupported tasks file extension %q. Use --format to specify one of: json, yaml, toml, markdown", filepath.Ext(filePath))
+	}
+}
+
+// LoadTasksFile reads a tasks file in the given format. An empty format is
+// detected from the file extension.
+func LoadTasksFile(filePath, format string) (*models.TasksFile, error) {
+	file, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("reading tasks file: %w", err)
+	}
+
+	if format == "" {
+		format, err = DetectTasksFormat(filePath)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return ParseTasks(file, format)
+}
+
+// ParseTasks decodes tasks file content in the given format.
+func ParseTasks(data []byte, format string) (*models.TasksFile, error) {
+	var tasksFile models.TasksFile
+	switch strings.ToLower(format) {
+	case FormatJSON:
+		if err := json.Unmarshal(data, &tasksFile); err != nil {
+			return nil, fmt.Errorf("parsing tasks JSON: %w", err)
+		}
+	case FormatYAML, "yml":
+		if err := yaml.Unmarshal(data, &tasksFile); err != nil {
+			return nil, fmt.Errorf("parsing tasks YAML: %w", err)
+		}
+	case FormatTOML:
+		if err := toml.Unmarshal(data, &tasksFile); err != nil {
+			return nil, fmt.Errorf("parsing tasks TOML: %w", err)
+		}
+	case FormatMarkdown, "md":
+		tasks, err := ParseMarkdownTasks(data)
+		if err != nil {
+			return nil, fmt.Errorf("parsing tasks Markdown: %w", err)
+		}
+		return tasks, nil
+	default:
+		return nil, fmt.Errorf("unsupported tasks file format %q. Expected one of: json, yaml, toml, markdown", format)
+	}
+
+	return &tasksFile, nil
+}
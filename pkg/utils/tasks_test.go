@@ -0,0 +1,51 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseTasksDueDates(t *testing.T) {
+	date := time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+	timestamp := time.Date(2024, 6, 30, 23, 59, 59, 0, time.UTC)
+
+	tests := []struct {
+		name, format, data string
+		want               time.Time
+	}{
+		{"json date", FormatJSON, `{"projectTitle": "P", "milestones": [{"title": "M", "due_on": "2024-06-30", "issues": []}]}`, date},
+		{"json timestamp", FormatJSON, `{"projectTitle": "P", "milestones": [{"title": "M", "due_on": "2024-06-30T23:59:59Z", "issues": []}]}`, timestamp},
+		{"yaml quoted date", FormatYAML, "projectTitle: P\nmilestones:\n  - title: M\n    due_on: \"2024-06-30\"\n", date},
+		{"yaml bare date", FormatYAML, "projectTitle: P\nmilestones:\n  - title: M\n    due_on: 2024-06-30\n", date},
+		{"yaml timestamp", FormatYAML, "projectTitle: P\nmilestones:\n  - title: M\n    due_on: 2024-06-30T23:59:59Z\n", timestamp},
+		{"toml quoted date", FormatTOML, "projectTitle = \"P\"\n[[milestones]]\ntitle = \"M\"\ndue_on = \"2024-06-30\"\n", date},
+		{"toml bare date", FormatTOML, "projectTitle = \"P\"\n[[milestones]]\ntitle = \"M\"\ndue_on = 2024-06-30\n", date},
+		{"toml quoted timestamp", FormatTOML, "projectTitle = \"P\"\n[[milestones]]\ntitle = \"M\"\ndue_on = \"2024-06-30T23:59:59Z\"\n", timestamp},
+		{"toml bare timestamp", FormatTOML, "projectTitle = \"P\"\n[[milestones]]\ntitle = \"M\"\ndue_on = 2024-06-30T23:59:59Z\n", timestamp},
+		{"toml local date time", FormatTOML, "projectTitle = \"P\"\n[[milestones]]\ntitle = \"M\"\ndue_on = 2024-06-30 23:59:59\n", timestamp},
+		{"markdown date", FormatMarkdown, "# P\n\n## M\ndue: 2024-06-30\n", date},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tasks, err := ParseTasks([]byte(tt.data), tt.format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := tasks.Milestones[0].DueOn.Time; !got.Equal(tt.want) {
+				t.Errorf("due_on = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTasksRejectsBadDueDate(t *testing.T) {
+	for format, data := range map[string]string{
+		FormatJSON: `{"projectTitle": "P", "milestones": [{"title": "M", "due_on": "June 30", "issues": []}]}`,
+		FormatYAML: "projectTitle: P\nmilestones:\n  - title: M\n    due_on: June 30\n",
+		FormatTOML: "projectTitle = \"P\"\n[[milestones]]\ntitle = \"M\"\ndue_on = \"June 30\"\n",
+	} {
+		if _, err := ParseTasks([]byte(data), format); err == nil {
+			t.Errorf("%s: expected an error for an unparseable due date", format)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// TokenOptions says where ResolveToken may look for a token.
+type TokenOptions struct {
+	// Host is the GitHub host the token is for.
+	Host string
+	// FlagFile is the --token-file flag. A token file named there has to
+	// hold a token.
+	FlagFile string
+	// ConfigFile is the config file's token_file. It is skipped if missing or
+	// if it has no token for Host.
+	ConfigFile string
+	// CredentialHelper is a shell command that prints a token.
+	CredentialHelper string
+}
+
+// ResolveToken returns the token for opts.Host from the first place that has
+// one, and a description of where that was. It looks, in order, at:
+//
+//  1. the --token-file flag
+//  2. GH_TOKEN or GITHUB_TOKEN (GH_ENTERPRISE_TOKEN or GITHUB_ENTERPRISE_TOKEN
+//     for GitHub Enterprise Server, as gh does)
+//  3. the config file's token file
+//  4. gh auth token, which reads gh's keyring or hosts file
+//  5. the credential helper
+func ResolveToken(opts TokenOptions) (string, string, error) {
+	names := tokenVariables(opts.Host)
+
+	if opts.FlagFile != "" {
+		token, err := readTokenFromFile(opts.FlagFile, names...)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to read token from %s: %w", opts.FlagFile, err)
+		}
+		return token, opts.FlagFile, nil
+	}
+
+	for _, name := range names {
+		if token := strings.TrimSpace(os.Getenv(name)); token != "" {
+			return token, name, nil
+		}
+	}
+
+	if opts.ConfigFile != "" {
+		token, err := readTokenFromFile(opts.ConfigFile, names...)
+		switch {
+		case err == nil:
+			return token, opts.ConfigFile, nil
+		case !errors.Is(err, os.ErrNotExist) && !errors.Is(err, errNoTokenInFile):
+			return "", "", fmt.Errorf("failed to read token from %s: %w", opts.ConfigFile, err)
+		}
+	}
+
+	if token, err := GetGitHubCLIToken(opts.Host); err == nil && token != "" {
+		return token, "gh auth token", nil
+	}
+
+	if opts.CredentialHelper != "" {
+		token, err := credentialHelperToken(opts.CredentialHelper, opts.Host)
+		if err != nil {
+			return "", "", err
+		}
+		return token, "credential helper", nil
+	}
+
+	return "", "", fmt.Errorf("GitHub token for %s not found. Set %s, authenticate with 'gh auth login --hostname %s', or provide a token file using the -f flag", opts.Host, names[0], opts.Host)
+}
+
+// tokenVariables returns the variables a token for host may be kept in, in
+// the order gh checks them. A GitHub Enterprise Server host never falls back
+// to GH_TOKEN, which usually holds a github.com token.
+func tokenVariables(host string) []string {
+	if host == "" || host == "github.com" || strings.HasSuffix(host, ".ghe.com") {
+		return []string{"GH_TOKEN", "GITHUB_TOKEN"}
+	}
+	return []string{"GH_ENTERPRISE_TOKEN", "GITHUB_ENTERPRISE_TOKEN"}
+}
+
+// credentialHelperToken runs helper through the shell with GH_HOST set and
+// returns the first line it prints.
+func credentialHelperToken(helper, host string) (string, error) {
+	cmd := exec.Command("sh", "-c", helper)
+	cmd.Env = append(os.Environ(), "GH_HOST="+host)
+	cmd.Stderr = os.Stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("credential helper failed: %w", err)
+	}
+	token, _, _ := strings.Cut(strings.TrimSpace(string(output)), "\n")
+	if token == "" {
+		return "", fmt.Errorf("credential helper printed no token")
+	}
+	return strings.TrimSpace(token), nil
+}
@@ -0,0 +1,75 @@
+package utils
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolveTokenKeepsGitHubTokenOffEnterpriseHosts(t *testing.T) {
+	t.Setenv("GH_TOKEN", "dotcom")
+	t.Setenv("GITHUB_TOKEN", "")
+	t.Setenv("GH_ENTERPRISE_TOKEN", "")
+	t.Setenv("GITHUB_ENTERPRISE_TOKEN", "")
+	// A PATH with sh for the credential helper but no gh to fall back on.
+	bin := t.TempDir()
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("no sh")
+	}
+	if err := os.Symlink(sh, filepath.Join(bin, "sh")); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin)
+
+	tokenFile := filepath.Join(t.TempDir(), ".token")
+	if err := os.WriteFile(tokenFile, []byte("GH_TOKEN=dotcom-file\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		host, token, source string
+	}{
+		{"github.com", "dotcom", "GH_TOKEN"},
+		{"acme.ghe.com", "dotcom", "GH_TOKEN"},
+		{"github.example.com", "ghes-helper", "credential helper"},
+	}
+	for _, tt := range tests {
+		token, source, err := ResolveToken(TokenOptions{
+			Host:             tt.host,
+			ConfigFile:       tokenFile,
+			CredentialHelper: "echo ghes-helper",
+		})
+		if err != nil {
+			t.Fatalf("%s: %v", tt.host, err)
+		}
+		if token != tt.token || source != tt.source {
+			t.Errorf("%s: got %q from %s, want %q from %s", tt.host, token, source, tt.token, tt.source)
+		}
+	}
+
+	t.Setenv("GH_ENTERPRISE_TOKEN", "ghes")
+	token, _, err := ResolveToken(TokenOptions{Host: "github.example.com"})
+	if err != nil || token != "ghes" {
+		t.Errorf("got %q, %v; want GH_ENTERPRISE_TOKEN", token, err)
+	}
+}
+
+func TestResolveTokenReadsEnterpriseLineFromTokenFile(t *testing.T) {
+	for _, name := range []string{"GH_TOKEN", "GITHUB_TOKEN", "GH_ENTERPRISE_TOKEN", "GITHUB_ENTERPRISE_TOKEN"} {
+		t.Setenv(name, "")
+	}
+
+	tokenFile := filepath.Join(t.TempDir(), ".token")
+	if err := os.WriteFile(tokenFile, []byte("GH_TOKEN=dotcom\nGH_ENTERPRISE_TOKEN=ghes\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	for host, want := range map[string]string{"github.com": "dotcom", "github.example.com": "ghes"} {
+		token, _, err := ResolveToken(TokenOptions{Host: host, FlagFile: tokenFile})
+		if err != nil || token != want {
+			t.Errorf("%s: got %q, %v; want %q", host, token, err, want)
+		}
+	}
+}
@@ -2,7 +2,6 @@ package utils
 
 import (
 	"bufio"
-	"encoding/json"
 	"fmt"
 	"os"
 	"os/exec"
@@ -10,7 +9,6 @@ import (
 	"strings"
 	"time"
 
-	"github.com/igorcosta/gh-lazy/pkg/models"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 )
@@ -35,40 +33,39 @@ func WrapError(err error, message string) error {
 	return errors.Wrap(err, message)
 }
 
+// errNoTokenInFile means a token file has no line for the host's token.
+var errNoTokenInFile = errors.New("token not found in file")
+
 func ReadTokenFromFile(filepath string) (string, error) {
-	file, err := os.Open(filepath)
+	return readTokenFromFile(filepath, "GH_TOKEN")
+}
+
+// readTokenFromFile returns the value of the first of names set in the file.
+func readTokenFromFile(filepath string, names ...string) (string, error) {
+	data, err := os.ReadFile(filepath)
 	if err != nil {
 		return "", fmt.Errorf("opening token file: %w", err)
 	}
-	defer file.Close()
 
-	scanner := bufio.NewScanner(file)
+	values := map[string]string{}
+	scanner := bufio.NewScanner(strings.NewReader(string(data)))
 	for scanner.Scan() {
-		line := scanner.Text()
-		if strings.HasPrefix(line, "GH_TOKEN=") {
-			return strings.TrimPrefix(line, "GH_TOKEN="), nil
+		if name, value, ok := strings.Cut(scanner.Text(), "="); ok {
+			if _, seen := values[name]; !seen {
+				values[name] = value
+			}
 		}
 	}
-
 	if err := scanner.Err(); err != nil {
 		return "", fmt.Errorf("reading token file: %w", err)
 	}
 
-	return "", fmt.Errorf("GH_TOKEN not found in file")
-}
-
-func LoadTasksFile(filePath string) (*models.TasksFile, error) {
-	file, err := os.ReadFile(filePath)
-	if err != nil {
-		return nil, fmt.Errorf("reading tasks JSON file: %w", err)
-	}
-
-	var tasksFile models.TasksFile
-	if err := json.Unmarshal(file, &tasksFile); err != nil {
-		return nil, fmt.Errorf("parsing tasks JSON: %w", err)
+	for _, name := range names {
+		if values[name] != "" {
+			return values[name], nil
+		}
 	}
-
-	return &tasksFile, nil
+	return "", fmt.Errorf("%w: %s", errNoTokenInFile, names[0])
 }
 
 func ShowProgress(progressChan <-chan string) {
@@ -106,7 +103,7 @@ Commands:
 
 Flags:
   -r, --repo string         The repository name (e.g., 'username/repo')
-  -t, --tasks string        Path to the tasks JSON file
+  -t, --tasks string        Path to the tasks file (JSON, YAML, TOML or Markdown)
   -f, --token-file string   Path to the file containing the GitHub token (default ".token")
 
 Use "gh lazy [command] --help" for more information about a command.
@@ -137,36 +134,16 @@ func ParseProjectID(input string) (string, error) {
 	return input, nil
 }
 
-func GetGitHubCLIToken() (string, error) {
-	cmd := exec.Command("gh", "auth", "token")
+// GetGitHubCLIToken returns the token gh is logged in with for host.
+func GetGitHubCLIToken(host string) (string, error) {
+	cmd := exec.Command("gh", "auth", "token", "--hostname", host)
 	output, err := cmd.Output()
 	if err != nil {
-		return "", fmt.Errorf("failed to get GitHub CLI token: %w", err)
+		return "", fmt.Errorf("failed to get GitHub CLI token for %s: %w", host, err)
 	}
 	return strings.TrimSpace(string(output)), nil
 }
 
-func GetToken(tokenFile string) (string, error) {
-	// First, try to get the token from the gh CLI
-	token, err := GetGitHubCLIToken()
-	if err == nil && token != "" {
-		return token, nil
-	}
-
-	// If gh CLI token is not available and tokenFile is provided, try to read from file
-	if tokenFile != "" {
-		token, err := ReadTokenFromFile(tokenFile)
-		if err == nil && token != "" {
-			return token, nil
-		}
-		// Only return an error if a token file was specified but couldn't be read
-		return "", fmt.Errorf("failed to read token from specified file: %w", err)
-	}
-
-	// If we've reached this point, no valid token was found
-	return "", fmt.Errorf("GitHub token not found. Please authenticate with 'gh auth login' or provide a token file using the -f flag")
-}
-
 func PrintUserGuide() {
 	fmt.Println(`To use gh lazy, please ensure you have:
 1. Authenticated with GitHub CLI using 'gh auth login', or